@@ -0,0 +1,303 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eficodec decodes and encodes the binary representation of
+// EFI structures declared as Go structs.
+//
+// Fields are encoded in declaration order.  Unsigned and signed
+// integers, booleans, arrays and nested structs are encoded in little
+// endian byte order without padding.  Unexported fields and fields
+// tagged with `efi:"-"` are skipped.  Variable-length fields are
+// described with the efi struct tag, which holds a comma-separated
+// list of options:
+//
+//	utf16z   null character terminated UTF-16 string
+//	asciiz   null byte terminated ASCII string
+//	utf16    unterminated UTF-16 string, the rest of the data unless
+//	         the size option is given
+//	ascii    unterminated ASCII string, the rest of the data unless
+//	         the size option is given
+//	ucs2     restricts utf16z and utf16 strings to UCS-2
+//	rest     slice holding the rest of the data
+//	size=F   slice holding the number of bytes given by the
+//	         preceding uint16 or uint32 field F
+//
+// String options apply to fields of the types []byte and string.
+// Byte slices keep the encoded form, including the terminator of
+// null terminated strings, the same way the types in efidevicepath
+// store them.  Strings are decoded and encoded strictly, see
+// efireader.DecodeUTF16, efireader.DecodeASCII and
+// efireader.EncodeUTF16.  Slices of other types hold as many elements
+// as the data they span contains.  Recursive struct types are not
+// supported.  Fields holding the rest of the data have
+// to be the last field of their struct.
+//
+//	type BootEntry struct {
+//		Attributes   uint32
+//		PathLength   uint16
+//		Description  string `efi:"utf16z"`
+//		Path         []byte `efi:"size=PathLength"`
+//		OptionalData []byte `efi:"rest"`
+//	}
+//
+// Encoding ignores the value of fields holding the size of another
+// field and writes the actual size instead.
+package eficodec
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+var (
+	ErrInvalidTag      = errors.New("invalid efi struct tag")
+	ErrUnsupportedType = errors.New("unsupported type")
+	ErrUnterminated    = errors.New("string is not null terminated")
+	ErrTrailingData    = errors.New("trailing data")
+)
+
+// encoding describes the encoding of a string field.
+type encoding uint8
+
+const (
+	rawEncoding encoding = iota
+	utf16zEncoding
+	asciizEncoding
+	utf16Encoding
+	asciiEncoding
+)
+
+// field describes how a single struct field is encoded.
+type field struct {
+	name  string
+	index int
+	typ   reflect.Type
+	enc   encoding
+
+	// mode is the character set of UTF-16 strings.
+	mode efireader.Encoding
+
+	// rest is set for fields holding the rest of the data.
+	rest bool
+
+	// size is the index into structPlan.fields of the field holding
+	// the size of this field in bytes, or -1.
+	size int
+
+	// sizeOf is set for fields holding the size of another field.
+	sizeOf bool
+}
+
+// structPlan describes how the fields of a struct type are encoded.
+type structPlan struct {
+	fields []field
+
+	// rest is set if the struct consumes the rest of the data.
+	rest bool
+}
+
+var plans sync.Map // map[reflect.Type]*structPlan
+
+// planFor returns the encoding plan of the struct type t.
+func planFor(t reflect.Type) (*structPlan, error) {
+	return planWithin(t, nil)
+}
+
+// planWithin returns the encoding plan of the struct type t nested
+// within the struct types outer, whose plans are being built.
+// Recursive types are rejected, as nothing guarantees that decoding
+// them terminates.
+func planWithin(t reflect.Type, outer []reflect.Type) (*structPlan, error) {
+	if p, ok := plans.Load(t); ok {
+		return p.(*structPlan), nil
+	}
+	for _, o := range outer {
+		if o == t {
+			return nil, fmt.Errorf("%w: recursive type %v", ErrUnsupportedType, t)
+		}
+	}
+
+	p, err := buildPlan(t, append(outer, t))
+	if err != nil {
+		return nil, err
+	}
+	plans.Store(t, p)
+	return p, nil
+}
+
+func buildPlan(t reflect.Type, outer []reflect.Type) (*structPlan, error) {
+	p := &structPlan{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("efi")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+
+		if p.rest {
+			return nil, fmt.Errorf("%s: %w: field follows rest of data", sf.Name, ErrInvalidTag)
+		}
+
+		f, err := parseField(p, sf, tag, outer)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sf.Name, err)
+		}
+		p.fields = append(p.fields, f)
+	}
+	return p, nil
+}
+
+// parseField parses the tag of the struct field sf and checks it
+// against the field's type.
+func parseField(p *structPlan, sf reflect.StructField, tag string, outer []reflect.Type) (f field, err error) {
+	f = field{name: sf.Name, index: sf.Index[0], typ: sf.Type, size: -1}
+
+	var sized bool
+	for _, opt := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(opt, "=")
+		switch name {
+		case "":
+		case "utf16z":
+			f.enc = utf16zEncoding
+		case "asciiz":
+			f.enc = asciizEncoding
+		case "utf16":
+			f.enc = utf16Encoding
+		case "ascii":
+			f.enc = asciiEncoding
+		case "ucs2":
+			f.mode = efireader.UCS2
+		case "rest":
+			f.rest, sized = true, true
+		case "size":
+			if f.size, err = sizeField(p, arg); err != nil {
+				return
+			}
+			sized = true
+		default:
+			return f, fmt.Errorf("%w: unknown option %q", ErrInvalidTag, name)
+		}
+	}
+
+	isBytes := sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Uint8
+	isString := sf.Type.Kind() == reflect.String
+
+	if f.mode == efireader.UCS2 && f.enc != utf16zEncoding && f.enc != utf16Encoding {
+		return f, fmt.Errorf("%w: ucs2 without utf16 or utf16z", ErrInvalidTag)
+	}
+
+	switch f.enc {
+	case utf16zEncoding, asciizEncoding:
+		if sized {
+			return f, fmt.Errorf("%w: terminated string with size", ErrInvalidTag)
+		}
+		if !isBytes && !isString {
+			return f, fmt.Errorf("%w: %v", ErrUnsupportedType, sf.Type)
+		}
+		return
+
+	case utf16Encoding, asciiEncoding:
+		if !isBytes && !isString {
+			return f, fmt.Errorf("%w: %v", ErrUnsupportedType, sf.Type)
+		}
+		if !sized {
+			f.rest = true
+		}
+
+	default:
+		if sized != (sf.Type.Kind() == reflect.Slice) {
+			return f, fmt.Errorf("%w: %v needs the rest or size option", ErrUnsupportedType, sf.Type)
+		}
+		if !sized {
+			if err = checkType(sf.Type, outer); err != nil {
+				return
+			}
+			if sf.Type.Kind() == reflect.Struct {
+				var sub *structPlan
+				if sub, err = planWithin(sf.Type, outer); err != nil {
+					return
+				}
+				f.rest = sub.rest
+			}
+			break
+		}
+		if err = checkType(sf.Type.Elem(), outer); err != nil {
+			return
+		}
+	}
+
+	if f.rest && f.size != -1 {
+		return f, fmt.Errorf("%w: rest with size", ErrInvalidTag)
+	}
+	p.rest = f.rest
+	return
+}
+
+// sizeField returns the index of the field called name holding the
+// size of another field.
+func sizeField(p *structPlan, name string) (int, error) {
+	for i := range p.fields {
+		f := &p.fields[i]
+		if f.name != name {
+			continue
+		}
+		if f.sizeOf {
+			return -1, fmt.Errorf("%w: size field %s used twice", ErrInvalidTag, name)
+		}
+		if k := f.typ.Kind(); k != reflect.Uint16 && k != reflect.Uint32 {
+			return -1, fmt.Errorf("%w: size field %s of type %v", ErrInvalidTag, name, f.typ)
+		}
+		f.sizeOf = true
+		return i, nil
+	}
+	return -1, fmt.Errorf("%w: no preceding size field %s", ErrInvalidTag, name)
+}
+
+// checkType checks whether values of the type t nested within the
+// struct types outer can be encoded on their own, that is without a
+// tag.
+func checkType(t reflect.Type, outer []reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return nil
+	case reflect.Array:
+		return checkType(t.Elem(), outer)
+	case reflect.Struct:
+		_, err := planWithin(t, outer)
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrUnsupportedType, t)
+}
+
+// structValue returns the struct value v points to or holds.
+func structValue(v any, settable bool) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	} else if settable {
+		return reflect.Value{}, fmt.Errorf("efi/codec: %w: %T, want non-nil struct pointer", ErrUnsupportedType, v)
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("efi/codec: %w: %T", ErrUnsupportedType, v)
+	}
+	return rv, nil
+}
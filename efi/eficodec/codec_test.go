@@ -0,0 +1,247 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eficodec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
+)
+
+type testEntry struct {
+	Attributes   uint32
+	PathLength   uint16
+	Description  string `efi:"utf16z"`
+	Path         []byte `efi:"size=PathLength"`
+	OptionalData []byte `efi:"rest"`
+}
+
+type testRecord struct {
+	Signed  int16
+	Flag    bool
+	Vendor  efiguid.GUID
+	Pair    [2]uint16
+	Name    []byte `efi:"asciiz"`
+	skipped int
+	Ignored int `efi:"-"`
+}
+
+type testNested struct {
+	Count   uint32
+	Records []testRecord `efi:"size=Count"`
+	Length  uint16
+	Label   string `efi:"ascii,size=Length"`
+	Tail    testTail
+}
+
+type testTail struct {
+	Text string `efi:"utf16"`
+}
+
+type testTree struct {
+	N    uint16
+	Kids []testTree `efi:"size=N"`
+}
+
+func TestRoundTrip(t *testing.T) {
+	guid := efiguid.MustFromString("8be4df61-93ca-11d2-aa0d-00e098032b8c")
+
+	tt := []struct {
+		name string
+		inp  any
+		want []byte
+	}{
+		{
+			name: "entry",
+			inp: &testEntry{
+				Attributes:   1,
+				PathLength:   4,
+				Description:  "a",
+				Path:         []byte{0x7f, 0xff, 0x04, 0x00},
+				OptionalData: []byte{0xaa},
+			},
+			want: []byte{
+				0x01, 0x00, 0x00, 0x00,
+				0x04, 0x00,
+				'a', 0x00, 0x00, 0x00,
+				0x7f, 0xff, 0x04, 0x00,
+				0xaa,
+			},
+		},
+		{
+			name: "empty rest",
+			inp:  &testEntry{Description: ""},
+			want: []byte{0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name: "record",
+			inp:  &testRecord{Signed: -2, Flag: true, Vendor: guid, Pair: [2]uint16{1, 2}, Name: []byte("x\x00")},
+			want: append(append([]byte{0xfe, 0xff, 0x01}, guid[:]...), 0x01, 0x00, 0x02, 0x00, 'x', 0x00),
+		},
+		{
+			name: "nested",
+			inp:  &testNested{Count: 48, Records: []testRecord{{Name: []byte("\x00")}, {Signed: 1, Name: []byte("\x00")}}, Length: 2, Label: "ok", Tail: testTail{Text: "ä"}},
+			want: bytes.Join([][]byte{
+				{0x30, 0x00, 0x00, 0x00},
+				make([]byte, 24),
+				{0x01}, make([]byte, 23),
+				{0x02, 0x00, 'o', 'k'},
+				{0xe4, 0x00},
+			}, nil),
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Marshal(tc.inp)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("Marshal() = %x, want %x", got, tc.want)
+			}
+
+			out := reflect.New(reflect.TypeOf(tc.inp).Elem())
+			if err := Unmarshal(got, out.Interface()); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(out.Interface(), tc.inp) {
+				t.Errorf("Unmarshal() = %+v, want %+v", out.Interface(), tc.inp)
+			}
+		})
+	}
+}
+
+func TestUnmarshal_SetsSize(t *testing.T) {
+	var got testEntry
+	if err := Unmarshal([]byte{0, 0, 0, 0, 2, 0, 0, 0, 1, 2, 3}, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := testEntry{PathLength: 2, Path: []byte{1, 2}, OptionalData: []byte{3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshal_Errors(t *testing.T) {
+	tt := []struct {
+		name    string
+		inp     []byte
+		out     any
+		wantErr error
+	}{
+		{"short", []byte{0, 0}, &testEntry{}, io.ErrUnexpectedEOF},
+		{"unterminated", []byte{0, 0, 0, 0, 0, 0, 'a', 0}, &testEntry{}, ErrUnterminated},
+		{"size exceeds data", []byte{0, 0, 0, 0, 9, 0, 0, 0}, &testEntry{}, io.ErrUnexpectedEOF},
+		{"trailing data", []byte{0, 0, 0}, &struct{ A uint16 }{}, ErrTrailingData},
+		{"partial item", []byte{3, 0, 0, 0, 1, 2, 3}, &struct {
+			N     uint32
+			Items []uint16 `efi:"size=N"`
+		}{}, io.ErrUnexpectedEOF},
+		{"ucs2 surrogate", []byte{0x3d, 0xd8, 0x00, 0xde, 0, 0}, &struct {
+			S string `efi:"utf16z,ucs2"`
+		}{}, efireader.ErrNotUCS2},
+		{"unpaired surrogate", []byte{0x3d, 0xd8}, &testTail{}, efireader.ErrInvalidSurrogate},
+		{"non-ascii asciiz", []byte{0xe4, 0}, &struct {
+			S string `efi:"asciiz"`
+		}{}, efireader.ErrNotASCII},
+		{"non-ascii ascii", []byte{'o', 0xe4}, &struct {
+			S string `efi:"ascii"`
+		}{}, efireader.ErrNotASCII},
+		{"recursive type", []byte{0, 0}, &testTree{}, ErrUnsupportedType},
+		{"not a pointer", nil, testEntry{}, ErrUnsupportedType},
+		{"not a struct", nil, new(int), ErrUnsupportedType},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Unmarshal(tc.inp, tc.out); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Unmarshal() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	tt := []struct {
+		name    string
+		inp     any
+		wantErr error
+	}{
+		{"unknown option", struct {
+			A uint8 `efi:"foo"`
+		}{}, ErrInvalidTag},
+		{"missing size field", struct {
+			A []byte `efi:"size=N"`
+		}{}, ErrInvalidTag},
+		{"size field after", struct {
+			A []byte `efi:"size=N"`
+			N uint16
+		}{}, ErrInvalidTag},
+		{"size field type", struct {
+			N uint8
+			A []byte `efi:"size=N"`
+		}{}, ErrInvalidTag},
+		{"field after rest", struct {
+			A []byte `efi:"rest"`
+			B uint8
+		}{}, ErrInvalidTag},
+		{"ucs2 ascii", struct {
+			A string `efi:"asciiz,ucs2"`
+		}{}, ErrInvalidTag},
+		{"non-ucs2", struct {
+			A string `efi:"utf16,ucs2"`
+		}{"😀"}, efireader.ErrNotUCS2},
+		{"untagged slice", struct{ A []byte }{}, ErrUnsupportedType},
+		{"string", struct{ A string }{}, ErrUnsupportedType},
+		{"int", struct{ A int }{}, ErrUnsupportedType},
+		{"recursive type", testTree{}, ErrUnsupportedType},
+		{"non-ascii", struct {
+			A string `efi:"asciiz"`
+		}{"ä"}, efiwriter.ErrNotASCII},
+		{"size overflow", struct {
+			N uint16
+			A []byte `efi:"size=N"`
+		}{A: make([]byte, 0x10000)}, efiwriter.ErrLengthOverflow},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Marshal(tc.inp); !errors.Is(err, tc.wantErr) {
+				t.Errorf("Marshal() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadFrom(t *testing.T) {
+	var got testTail
+	n, err := ReadFrom(strings.NewReader("a\x00"), &got)
+	if err != nil || n != 2 || got.Text != "a" {
+		t.Errorf("ReadFrom() = %d, %v, %+v", n, err, got)
+	}
+
+	var buf bytes.Buffer
+	if n, err := WriteTo(&buf, got); err != nil || n != 2 || buf.String() != "a\x00" {
+		t.Errorf("WriteTo() = %d, %v, %q", n, err, buf.String())
+	}
+}
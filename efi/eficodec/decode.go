@@ -0,0 +1,220 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eficodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+// Unmarshal decodes the binary representation of the struct v points
+// to from b.  All of b has to be consumed, ErrTrailingData is returned
+// otherwise.
+func Unmarshal(b []byte, v any) error {
+	rv, err := structValue(v, true)
+	if err != nil {
+		return err
+	}
+
+	d := decoder{b: b}
+	if err := d.decodeStruct(rv); err != nil {
+		return fmt.Errorf("efi/codec: %v: %w", rv.Type(), err)
+	}
+	if len(d.b) > 0 {
+		return fmt.Errorf("efi/codec: %v: %d bytes: %w", rv.Type(), len(d.b), ErrTrailingData)
+	}
+	return nil
+}
+
+// decoder consumes the data b while decoding values.
+type decoder struct {
+	b []byte
+}
+
+// take consumes the next n bytes.
+func (d *decoder) take(n int) ([]byte, error) {
+	if n > len(d.b) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	out := d.b[:n:n]
+	d.b = d.b[n:]
+	return out, nil
+}
+
+func (d *decoder) decodeStruct(v reflect.Value) error {
+	p, err := planFor(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range p.fields {
+		if err := d.decodeField(v, p, f); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) decodeField(v reflect.Value, p *structPlan, f field) (err error) {
+	fv := v.Field(f.index)
+
+	var data []byte
+	switch {
+	case f.enc == utf16zEncoding:
+		data, rest, ok := efireader.CutUTF16Null(d.b)
+		if !ok {
+			return ErrUnterminated
+		}
+		d.b = rest
+		if fv.Kind() == reflect.String {
+			s, err := efireader.DecodeUTF16Z(data, f.mode)
+			if err != nil {
+				return err
+			}
+			fv.SetString(s)
+		} else {
+			fv.SetBytes(append([]byte(nil), data...))
+		}
+		return nil
+
+	case f.enc == asciizEncoding:
+		data, rest, ok := efireader.CutASCIINull(d.b)
+		if !ok {
+			return ErrUnterminated
+		}
+		d.b = rest
+		if fv.Kind() == reflect.String {
+			s, err := efireader.DecodeASCIIZ(data)
+			if err != nil {
+				return err
+			}
+			fv.SetString(s)
+		} else {
+			fv.SetBytes(append([]byte(nil), data...))
+		}
+		return nil
+
+	case f.rest && fv.Kind() != reflect.Struct:
+		data, d.b = d.b, nil
+
+	case f.size != -1:
+		n := v.Field(p.fields[f.size].index).Uint()
+		if n > uint64(len(d.b)) {
+			return io.ErrUnexpectedEOF
+		}
+		data, _ = d.take(int(n))
+
+	default:
+		return d.decodeValue(fv)
+	}
+
+	switch {
+	case f.enc == utf16Encoding && fv.Kind() == reflect.String:
+		s, err := efireader.DecodeUTF16(data, f.mode)
+		if err != nil {
+			return err
+		}
+		fv.SetString(s)
+	case fv.Kind() == reflect.String:
+		s, err := efireader.DecodeASCII(data)
+		if err != nil {
+			return err
+		}
+		fv.SetString(s)
+	case fv.Type().Elem().Kind() == reflect.Uint8:
+		fv.SetBytes(append([]byte(nil), data...))
+	default:
+		return decodeSlice(fv, data)
+	}
+	return nil
+}
+
+// decodeSlice decodes the elements of the slice v from data.
+func decodeSlice(v reflect.Value, data []byte) error {
+	out := reflect.MakeSlice(v.Type(), 0, 0)
+	for d := (decoder{b: data}); len(d.b) > 0; {
+		elem, left := reflect.New(v.Type().Elem()).Elem(), len(d.b)
+		if err := d.decodeValue(elem); err != nil {
+			return fmt.Errorf("item #%d: %w", out.Len(), err)
+		}
+		if len(d.b) == left {
+			return fmt.Errorf("%w: zero-sized %v", ErrUnsupportedType, elem.Type())
+		}
+		out = reflect.Append(out, elem)
+	}
+	if out.Len() > 0 {
+		v.Set(out)
+	} else {
+		v.Set(reflect.Zero(v.Type()))
+	}
+	return nil
+}
+
+// decodeValue decodes a value encoded without a tag.
+func (d *decoder) decodeValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return d.decodeStruct(v)
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, err := d.take(v.Len())
+			if err != nil {
+				return err
+			}
+			reflect.Copy(v, reflect.ValueOf(b))
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := d.decodeValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	b, err := d.take(int(v.Type().Size()))
+	if err != nil {
+		return err
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(b[0] != 0)
+	case reflect.Uint8:
+		v.SetUint(uint64(b[0]))
+	case reflect.Uint16:
+		v.SetUint(uint64(binary.LittleEndian.Uint16(b)))
+	case reflect.Uint32:
+		v.SetUint(uint64(binary.LittleEndian.Uint32(b)))
+	case reflect.Uint64:
+		v.SetUint(binary.LittleEndian.Uint64(b))
+	case reflect.Int8:
+		v.SetInt(int64(int8(b[0])))
+	case reflect.Int16:
+		v.SetInt(int64(int16(binary.LittleEndian.Uint16(b))))
+	case reflect.Int32:
+		v.SetInt(int64(int32(binary.LittleEndian.Uint32(b))))
+	case reflect.Int64:
+		v.SetInt(int64(binary.LittleEndian.Uint64(b)))
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, v.Type())
+	}
+	return nil
+}
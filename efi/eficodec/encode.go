@@ -0,0 +1,203 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eficodec
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
+)
+
+// Marshal returns the binary representation of the struct v or the
+// struct v points to.
+func Marshal(v any) ([]byte, error) {
+	var e encoder
+	if err := e.encode(v); err != nil {
+		return nil, err
+	}
+	return e.b.Bytes(), nil
+}
+
+// WriteTo writes the binary representation of the struct v or the
+// struct v points to to w.
+func WriteTo(w io.Writer, v any) (n int64, err error) {
+	var e encoder
+	if err = e.encode(v); err != nil {
+		return
+	}
+	return e.b.WriteTo(w)
+}
+
+// ReadFrom reads all data from r and decodes it into the struct v
+// points to, see Unmarshal.
+func ReadFrom(r io.Reader, v any) (n int64, err error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return int64(len(b)), err
+	}
+	return int64(len(b)), Unmarshal(b, v)
+}
+
+// lengthField is a reserved field of an efiwriter.Buffer holding the
+// size of another field.
+type lengthField interface {
+	SetLength(start int) error
+}
+
+// encoder collects the encoded values.
+type encoder struct {
+	b efiwriter.Buffer
+}
+
+func (e *encoder) encode(v any) error {
+	rv, err := structValue(v, false)
+	if err != nil {
+		return err
+	}
+
+	// Work on an addressable copy, so byte arrays can be sliced.
+	if !rv.CanAddr() {
+		cp := reflect.New(rv.Type()).Elem()
+		cp.Set(rv)
+		rv = cp
+	}
+
+	if err := e.encodeStruct(rv); err != nil {
+		return fmt.Errorf("efi/codec: %v: %w", rv.Type(), err)
+	}
+	return nil
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) error {
+	p, err := planFor(v.Type())
+	if err != nil {
+		return err
+	}
+
+	var lengths []lengthField
+	for i, f := range p.fields {
+		if f.sizeOf {
+			if lengths == nil {
+				lengths = make([]lengthField, len(p.fields))
+			}
+			if f.typ.Kind() == reflect.Uint16 {
+				lengths[i] = e.b.ReserveUint16()
+			} else {
+				lengths[i] = e.b.ReserveUint32()
+			}
+			continue
+		}
+
+		start := e.b.Len()
+		if err := e.encodeField(v.Field(f.index), f); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
+		if f.size != -1 {
+			if err := lengths[f.size].SetLength(start); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *encoder) encodeField(v reflect.Value, f field) (err error) {
+	var b []byte
+	switch {
+	case f.enc == rawEncoding && v.Kind() == reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			e.b.Write(v.Bytes())
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err = e.encodeValue(v.Index(i)); err != nil {
+				return fmt.Errorf("item #%d: %w", i, err)
+			}
+		}
+		return nil
+
+	case f.enc == rawEncoding:
+		return e.encodeValue(v)
+
+	case v.Kind() == reflect.Slice:
+		b = v.Bytes()
+		switch f.enc {
+		case utf16zEncoding:
+			b = efiwriter.TerminateUTF16(b)
+		case asciizEncoding:
+			b = efiwriter.TerminateASCII(b)
+		}
+
+	case f.enc == utf16zEncoding:
+		if b, err = efireader.EncodeUTF16Z(v.String(), f.mode); err != nil {
+			return
+		}
+
+	case f.enc == utf16Encoding:
+		if b, err = efireader.EncodeUTF16(v.String(), f.mode); err != nil {
+			return
+		}
+
+	default:
+		if b, err = efiwriter.StringToASCIIZBytes(v.String()); err != nil {
+			return
+		}
+		if f.enc == asciiEncoding {
+			b = b[:len(b)-1]
+		}
+	}
+
+	e.b.Write(b)
+	return nil
+}
+
+// encodeValue encodes a value without a tag.
+func (e *encoder) encodeValue(v reflect.Value) error {
+	var buf [8]byte
+	switch v.Kind() {
+	case reflect.Struct:
+		return e.encodeStruct(v)
+
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 && v.CanAddr() {
+			e.b.Write(v.Slice(0, v.Len()).Bytes())
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := e.encodeValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Bool:
+		if v.Bool() {
+			buf[0] = 1
+		}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		binary.LittleEndian.PutUint64(buf[:], v.Uint())
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(v.Int()))
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, v.Type())
+	}
+
+	e.b.Write(buf[:v.Type().Size()])
+	return nil
+}
@@ -69,6 +69,12 @@ func (u GUID) Braced() string {
 	return strings.ToUpper(string(buf[:]))
 }
 
+// MarshalText implements encoding.TextMarshaler and returns the
+// same representation as String.
+func (u GUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
 func swapEndianness(buf []byte) []byte {
 	for i := 0; i < len(buf)/2; i++ {
 		buf[i], buf[len(buf)-i-1] = buf[len(buf)-i-1], buf[i]
@@ -62,3 +62,15 @@ func TestGUID_Braced(t *testing.T) {
 		})
 	}
 }
+
+func TestGUID_MarshalText(t *testing.T) {
+	u := MustFromString("8be4df61-93ca-11d2-aa0d-00e098032b8c")
+
+	got, err := u.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "8BE4DF61-93CA-11D2-AA0D-00E098032B8C", string(got))
+
+	var gotUU GUID
+	require.NoError(t, gotUU.UnmarshalText(got))
+	require.Equal(t, u, gotUU)
+}
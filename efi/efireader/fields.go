@@ -18,6 +18,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"reflect"
 )
 
 type FieldReader struct {
@@ -31,13 +32,28 @@ func (r *FieldReader) Read(p []byte) (n int, err error) {
 	return
 }
 
+// ReadByte implements io.ByteReader, reading single bytes without
+// going through Read if the underlying reader supports it.
+func (r *FieldReader) ReadByte() (b byte, err error) {
+	if br, ok := r.reader.(io.ByteReader); ok {
+		if b, err = br.ReadByte(); err == nil {
+			*r.offset++
+		}
+		return
+	}
+
+	var block [1]byte
+	_, err = io.ReadFull(r, block[:])
+	return block[0], err
+}
+
 func (r *FieldReader) Offset() int64 {
 	return *r.offset
 }
 
 func (r *FieldReader) ReadFields(fields ...any) (err error) {
 	for i, d := range fields {
-		if err = binary.Read(r, binary.LittleEndian, d); err != nil {
+		if err = r.readField(d); err != nil {
 			err = fmt.Errorf("field #%d: %w", i, err)
 			return
 		}
@@ -45,6 +61,50 @@ func (r *FieldReader) ReadFields(fields ...any) (err error) {
 	return
 }
 
+// readField reads a single field in little endian byte order.
+// Unsigned integers and byte arrays, the most common fields, are
+// decoded without the allocations binary.Read needs for them.
+func (r *FieldReader) readField(d any) error {
+	var (
+		buf [8]byte
+		n   int
+	)
+	switch d.(type) {
+	case *uint8:
+		n = 1
+	case *uint16:
+		n = 2
+	case *uint32:
+		n = 4
+	case *uint64:
+		n = 8
+	default:
+		// Byte arrays, like GUIDs and addresses, are read in place.
+		if v := reflect.ValueOf(d); v.Kind() == reflect.Pointer &&
+			v.Elem().Kind() == reflect.Array && v.Elem().Type().Elem().Kind() == reflect.Uint8 {
+			_, err := io.ReadFull(r, v.Elem().Slice(0, v.Elem().Len()).Bytes())
+			return err
+		}
+		return binary.Read(r, binary.LittleEndian, d)
+	}
+
+	if _, err := io.ReadFull(r, buf[:n]); err != nil {
+		return err
+	}
+
+	switch v := d.(type) {
+	case *uint8:
+		*v = buf[0]
+	case *uint16:
+		*v = binary.LittleEndian.Uint16(buf[:])
+	case *uint32:
+		*v = binary.LittleEndian.Uint32(buf[:])
+	case *uint64:
+		*v = binary.LittleEndian.Uint64(buf[:])
+	}
+	return nil
+}
+
 func NewFieldReader(reader io.Reader, offset *int64) *FieldReader {
 	if offset == nil {
 		offset = new(int64)
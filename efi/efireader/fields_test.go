@@ -0,0 +1,73 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efireader
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestReadFields(t *testing.T) {
+	type pair struct{ A, B uint8 }
+
+	var (
+		u8   uint8
+		u16  uint16
+		u32  uint32
+		u64  uint64
+		arr  [3]byte
+		st   pair
+		i16  int16
+		inp  = []byte{1, 2, 0, 3, 0, 0, 0, 4, 0, 0, 0, 0, 0, 0, 0, 5, 6, 7, 8, 9, 0xfe, 0xff}
+		want = pair{8, 9}
+	)
+
+	n, err := ReadFields(bytes.NewReader(inp), &u8, &u16, &u32, &u64, &arr, &st, &i16)
+	if err != nil {
+		t.Fatalf("ReadFields() error = %v", err)
+	}
+	if n != int64(len(inp)) {
+		t.Errorf("ReadFields() n = %d, want %d", n, len(inp))
+	}
+	if u8 != 1 || u16 != 2 || u32 != 3 || u64 != 4 || arr != [3]byte{5, 6, 7} || st != want || i16 != -2 {
+		t.Errorf("ReadFields() = %v %v %v %v %v %v %v", u8, u16, u32, u64, arr, st, i16)
+	}
+
+	if _, err := ReadFields(bytes.NewReader([]byte{1}), &u16); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ReadFields() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func TestFieldReader_ReadByte(t *testing.T) {
+	for _, r := range []io.Reader{
+		bytes.NewReader([]byte{0xaa}),
+		io.LimitReader(bytes.NewReader([]byte{0xaa}), 1),
+	} {
+		var n int64
+		fr := NewFieldReader(r, &n)
+
+		if c, err := fr.ReadByte(); err != nil || c != 0xaa {
+			t.Errorf("ReadByte() = %v, %v, want 0xaa", c, err)
+		}
+		if _, err := fr.ReadByte(); err != io.EOF {
+			t.Errorf("ReadByte() error = %v, want %v", err, io.EOF)
+		}
+		if n != 1 {
+			t.Errorf("Offset() = %d, want 1", n)
+		}
+	}
+}
@@ -22,20 +22,61 @@ import (
 	"unicode/utf16"
 )
 
+// ReadASCIINullBytes reads a null byte terminated ASCII string from r
+// and returns it including its terminator.
 func ReadASCIINullBytes(r io.Reader) (out []byte, err error) {
-	var block [1]byte
+	br := byteReader(r)
 	for {
-		if _, err = io.ReadFull(r, block[:]); err != nil {
+		var c byte
+		if c, err = br.ReadByte(); err != nil {
+			if err == io.EOF && len(out) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
 			return
 		}
 
-		out = append(out, block[:]...)
-		if bytes.Equal(block[:], []byte{0x00}) {
+		out = append(out, c)
+		if c == 0x00 {
 			return
 		}
 	}
 }
 
+// CutASCIINull slices a null byte terminated ASCII string including
+// its terminator from the beginning of b without copying it and
+// returns it together with the remainder of b.  ok is false if b
+// contains no null byte.
+func CutASCIINull(b []byte) (s, rest []byte, ok bool) {
+	if i := bytes.IndexByte(b, 0x00); i != -1 {
+		return b[:i+1], b[i+1:], true
+	}
+	return b, nil, false
+}
+
+// DecodeASCII decodes the unterminated ASCII byte sequence b.  Unlike
+// a conversion to string it fails on bytes outside of ASCII.
+func DecodeASCII(b []byte) (string, error) {
+	for i, c := range b {
+		if c >= 0x80 {
+			return "", &StringError{Offset: i, Err: ErrNotASCII}
+		}
+	}
+	return string(b), nil
+}
+
+// DecodeASCIIZ decodes the null byte terminated ASCII byte sequence
+// b, which has to end with its only null byte.
+func DecodeASCIIZ(b []byte) (string, error) {
+	s, rest, ok := CutASCIINull(b)
+	switch {
+	case !ok:
+		return "", &StringError{Offset: len(b), Err: ErrMissingTerminator}
+	case len(rest) > 0:
+		return "", &StringError{Offset: len(s) - 1, Err: ErrNullCharacter}
+	}
+	return DecodeASCII(s[:len(s)-1])
+}
+
 // ASCIIZBytesToString decodes a null byte terminated ascii byte sequence to a string.
 func ASCIIZBytesToString(b []byte) (s string) {
 	if i := bytes.IndexByte(b, 0); i != -1 {
@@ -44,25 +85,72 @@ func ASCIIZBytesToString(b []byte) (s string) {
 	return string(b)
 }
 
+// ReadUTF16NullBytes reads a null character terminated UTF-16 string
+// from r and returns it including its terminator.
 func ReadUTF16NullBytes(r io.Reader) (out []byte, err error) {
-	var block [2]byte
+	br := byteReader(r)
 	for {
-		if _, err = io.ReadFull(r, block[:]); err != nil {
+		var lo, hi byte
+		if lo, err = br.ReadByte(); err != nil {
+			if err == io.EOF && len(out) > 0 {
+				err = io.ErrUnexpectedEOF
+			}
+			return
+		}
+		if hi, err = br.ReadByte(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
 			return
 		}
 
-		out = append(out, block[:]...)
-		if bytes.Equal(block[:], []byte{0x00, 0x00}) {
+		out = append(out, lo, hi)
+		if lo == 0x00 && hi == 0x00 {
 			return
 		}
 	}
 }
 
+// CutUTF16Null slices a null character terminated UTF-16 string
+// including its terminator from the beginning of b without copying
+// it and returns it together with the remainder of b.  ok is false
+// if b contains no null character.
+func CutUTF16Null(b []byte) (s, rest []byte, ok bool) {
+	for i := 0; i+1 < len(b); i += 2 {
+		if b[i] == 0x00 && b[i+1] == 0x00 {
+			return b[:i+2], b[i+2:], true
+		}
+	}
+	return b, nil, false
+}
+
+// byteReader returns r as io.ByteReader, wrapping it if necessary.
+func byteReader(r io.Reader) io.ByteReader {
+	if br, ok := r.(io.ByteReader); ok {
+		return br
+	}
+	return &singleByteReader{r: r}
+}
+
+// singleByteReader implements io.ByteReader on top of an io.Reader
+// without reading ahead.
+type singleByteReader struct {
+	r     io.Reader
+	block [1]byte
+}
+
+func (r *singleByteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(r.r, r.block[:])
+	return r.block[0], err
+}
+
 // UTF16BytesToString decodes an unterminated UTF-16 byte sequence to a string.
+// A trailing odd byte is ignored and unpaired surrogates are replaced
+// with U+FFFD, use DecodeUTF16 to detect invalid input.
 func UTF16BytesToString(b []byte) string {
 	out := make([]uint16, len(b)>>1)
-	for i, j := 0, 0; j < len(b); i, j = i+1, j+2 {
-		out[i] = binary.LittleEndian.Uint16(b[j:])
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint16(b[i*2:])
 	}
 	return string(utf16.Decode(out))
 }
@@ -16,6 +16,8 @@ package efireader
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"reflect"
 	"testing"
 )
@@ -104,6 +106,7 @@ func TestUTF16BytesToString(t *testing.T) {
 		expected string
 	}{
 		{"", []byte{0x74, 0x00, 0x65, 0x00, 0x73, 0x00, 0x74, 0x00}, "test"},
+		{"odd length", []byte{0x74, 0x00, 0x65}, "t"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -113,3 +116,116 @@ func TestUTF16BytesToString(t *testing.T) {
 		})
 	}
 }
+
+func TestCutUTF16Null(t *testing.T) {
+	tests := []struct {
+		name     string
+		inp      []byte
+		wantS    []byte
+		wantRest []byte
+		wantOk   bool
+	}{
+		{"golden path", []byte{0x61, 0x00, 0x00, 0x00, 0x01}, []byte{0x61, 0x00, 0x00, 0x00}, []byte{0x01}, true},
+		{"unaligned null", []byte{0x00, 0x61, 0x00, 0x00, 0x00}, []byte{0x00, 0x61, 0x00, 0x00}, []byte{0x00}, true},
+		{"unterminated", []byte{0x61, 0x00, 0x00}, []byte{0x61, 0x00, 0x00}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, rest, ok := CutUTF16Null(tt.inp)
+			if !reflect.DeepEqual(s, tt.wantS) || !reflect.DeepEqual(rest, tt.wantRest) || ok != tt.wantOk {
+				t.Errorf("CutUTF16Null() = %v, %v, %v, want %v, %v, %v", s, rest, ok, tt.wantS, tt.wantRest, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCutASCIINull(t *testing.T) {
+	s, rest, ok := CutASCIINull([]byte{'a', 0x00, 'b'})
+	if string(s) != "a\x00" || string(rest) != "b" || !ok {
+		t.Errorf("CutASCIINull() = %q, %q, %v", s, rest, ok)
+	}
+
+	if _, _, ok := CutASCIINull([]byte{'a'}); ok {
+		t.Errorf("CutASCIINull() ok = true for unterminated string")
+	}
+}
+
+func TestDecodeASCIIZ(t *testing.T) {
+	tests := []struct {
+		name    string
+		inp     []byte
+		want    string
+		wantErr error
+	}{
+		{"golden path", []byte("abc\x00"), "abc", nil},
+		{"empty", []byte{0x00}, "", nil},
+		{"unterminated", []byte("abc"), "", ErrMissingTerminator},
+		{"trailing data", []byte("a\x00b\x00"), "", ErrNullCharacter},
+		{"non-ascii", []byte("a\xff\x00"), "", ErrNotASCII},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeASCIIZ(tt.inp)
+			if got != tt.want || !errors.Is(err, tt.wantErr) {
+				t.Errorf("DecodeASCIIZ() = %q, %v, want %q, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReadASCIINullBytes(t *testing.T) {
+	for _, r := range []io.Reader{
+		bytes.NewReader([]byte{'a', 's', 'd', 0x00, 'x'}),
+		io.LimitReader(bytes.NewReader([]byte{'a', 's', 'd', 0x00, 'x'}), 5),
+	} {
+		gotOut, err := ReadASCIINullBytes(r)
+		if err != nil {
+			t.Fatalf("ReadASCIINullBytes() error = %v", err)
+		}
+		if string(gotOut) != "asd\x00" {
+			t.Errorf("ReadASCIINullBytes() gotOut = %q, want %q", gotOut, "asd\x00")
+		}
+	}
+
+	if _, err := ReadASCIINullBytes(bytes.NewReader([]byte{'a'})); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadASCIINullBytes() error = %v, want %v", err, io.ErrUnexpectedEOF)
+	}
+}
+
+func BenchmarkReadUTF16NullBytes(b *testing.B) {
+	inp, err := EncodeUTF16Z(`\EFI\Microsoft\Boot\bootmgfw.efi`, UTF16)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("ByteReader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(inp)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadUTF16NullBytes(bytes.NewReader(inp)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Reader", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(inp)))
+		for i := 0; i < b.N; i++ {
+			if _, err := ReadUTF16NullBytes(io.LimitReader(bytes.NewReader(inp), int64(len(inp)))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Cut", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(inp)))
+		for i := 0; i < b.N; i++ {
+			if _, _, ok := CutUTF16Null(inp); !ok {
+				b.Fatal("unterminated")
+			}
+		}
+	})
+}
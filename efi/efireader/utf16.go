@@ -0,0 +1,158 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efireader
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	ErrOddLength         = errors.New("odd length of UTF-16 sequence")
+	ErrInvalidSurrogate  = errors.New("unpaired surrogate")
+	ErrMissingTerminator = errors.New("missing null terminator")
+	ErrNullCharacter     = errors.New("null character inside string")
+	ErrNotUCS2           = errors.New("character outside of UCS-2")
+	ErrInvalidUTF8       = errors.New("invalid UTF-8 sequence")
+	ErrNotASCII          = errors.New("string contains a non-ASCII character")
+)
+
+// Encoding selects the character set of 16-bit strings.
+type Encoding uint8
+
+const (
+	// UTF16 encodes characters outside the basic multilingual plane
+	// as surrogate pairs.
+	UTF16 Encoding = iota
+
+	// UCS2 only allows characters of the basic multilingual plane,
+	// each encoded as a single 16-bit code unit.  The specification
+	// uses it for CHAR16 strings unless otherwise specified.
+	//
+	// Section 2.3.1 "Data Types"
+	UCS2
+)
+
+// StringError describes an invalid string.
+type StringError struct {
+	// Offset is the offset in bytes of the invalid character.
+	Offset int
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *StringError) Error() string {
+	return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *StringError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeUTF16 decodes the unterminated little endian UTF-16 or UCS-2
+// byte sequence b.  Unlike UTF16BytesToString it fails on invalid
+// input instead of replacing or dropping it.
+func DecodeUTF16(b []byte, enc Encoding) (string, error) {
+	if len(b)%2 != 0 {
+		return "", &StringError{Offset: len(b) - 1, Err: ErrOddLength}
+	}
+
+	out := make([]byte, 0, len(b)/2)
+	for i := 0; i < len(b); i += 2 {
+		r := rune(binary.LittleEndian.Uint16(b[i:]))
+		if utf16.IsSurrogate(r) {
+			if enc == UCS2 {
+				return "", &StringError{Offset: i, Err: ErrNotUCS2}
+			}
+
+			// A high surrogate has to be followed by a low one.
+			if r >= 0xdc00 || i+4 > len(b) {
+				return "", &StringError{Offset: i, Err: ErrInvalidSurrogate}
+			}
+			if r = utf16.DecodeRune(r, rune(binary.LittleEndian.Uint16(b[i+2:]))); r == utf8.RuneError {
+				return "", &StringError{Offset: i, Err: ErrInvalidSurrogate}
+			}
+			i += 2
+		}
+		out = utf8.AppendRune(out, r)
+	}
+	return string(out), nil
+}
+
+// DecodeUTF16Z decodes the null character terminated little endian
+// UTF-16 or UCS-2 byte sequence b, which has to end with its only
+// null character.
+func DecodeUTF16Z(b []byte, enc Encoding) (string, error) {
+	s, rest, ok := CutUTF16Null(b)
+	switch {
+	case !ok && len(b)%2 != 0:
+		return "", &StringError{Offset: len(b) - 1, Err: ErrOddLength}
+	case !ok:
+		return "", &StringError{Offset: len(b), Err: ErrMissingTerminator}
+	case len(rest) > 0:
+		return "", &StringError{Offset: len(s) - 2, Err: ErrNullCharacter}
+	}
+	return DecodeUTF16(s[:len(s)-2], enc)
+}
+
+// EncodeUTF16 encodes s to an unterminated little endian UTF-16 or
+// UCS-2 byte sequence.  Offsets of errors refer to s.
+func EncodeUTF16(s string, enc Encoding) ([]byte, error) {
+	return appendUTF16(make([]byte, 0, len(s)*2), s, enc)
+}
+
+// EncodeUTF16Z encodes s to a null character terminated little
+// endian UTF-16 or UCS-2 byte sequence.  s must not contain null
+// characters.  Offsets of errors refer to s.
+func EncodeUTF16Z(s string, enc Encoding) ([]byte, error) {
+	out, err := appendUTF16(make([]byte, 0, len(s)*2+2), s, enc)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x00 {
+			return nil, &StringError{Offset: i, Err: ErrNullCharacter}
+		}
+	}
+	return append(out, 0x00, 0x00), nil
+}
+
+func appendUTF16(out []byte, s string, enc Encoding) ([]byte, error) {
+	for i, r := range s {
+		switch {
+		case r == utf8.RuneError && !isRuneError(s[i:]):
+			return nil, &StringError{Offset: i, Err: ErrInvalidUTF8}
+		case r > 0xffff && enc == UCS2:
+			return nil, &StringError{Offset: i, Err: ErrNotUCS2}
+		case r > 0xffff:
+			hi, lo := utf16.EncodeRune(r)
+			out = append(out, byte(hi), byte(hi>>8), byte(lo), byte(lo>>8))
+		default:
+			out = append(out, byte(r), byte(r>>8))
+		}
+	}
+	return out, nil
+}
+
+// isRuneError reports whether s starts with an encoded U+FFFD rather
+// than an invalid UTF-8 sequence.
+func isRuneError(s string) bool {
+	_, size := utf8.DecodeRuneInString(s)
+	return size == 3
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efireader
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDecodeUTF16(t *testing.T) {
+	tt := []struct {
+		name       string
+		inp        []byte
+		enc        Encoding
+		want       string
+		wantErr    error
+		wantOffset int
+	}{
+		{"empty", nil, UTF16, "", nil, 0},
+		{"bmp", []byte{'a', 0x00, 0xe4, 0x00}, UCS2, "aä", nil, 0},
+		{"surrogate pair", []byte{'a', 0x00, 0x3d, 0xd8, 0x00, 0xde}, UTF16, "a😀", nil, 0},
+		{"surrogate pair ucs2", []byte{'a', 0x00, 0x3d, 0xd8, 0x00, 0xde}, UCS2, "", ErrNotUCS2, 2},
+		{"odd length", []byte{'a', 0x00, 'b'}, UTF16, "", ErrOddLength, 2},
+		{"lone high surrogate", []byte{0x3d, 0xd8}, UTF16, "", ErrInvalidSurrogate, 0},
+		{"high surrogate without low", []byte{0x3d, 0xd8, 'a', 0x00}, UTF16, "", ErrInvalidSurrogate, 0},
+		{"lone low surrogate", []byte{'a', 0x00, 0x00, 0xde}, UTF16, "", ErrInvalidSurrogate, 2},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeUTF16(tc.inp, tc.enc)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("DecodeUTF16() error = %v, want %v", err, tc.wantErr)
+			}
+			var serr *StringError
+			if errors.As(err, &serr) && serr.Offset != tc.wantOffset {
+				t.Errorf("DecodeUTF16() offset = %d, want %d", serr.Offset, tc.wantOffset)
+			}
+			if got != tc.want {
+				t.Errorf("DecodeUTF16() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecodeUTF16Z(t *testing.T) {
+	tt := []struct {
+		name    string
+		inp     []byte
+		want    string
+		wantErr error
+	}{
+		{"golden path", []byte{'a', 0x00, 0x00, 0x00}, "a", nil},
+		{"empty", []byte{0x00, 0x00}, "", nil},
+		{"unterminated", []byte{'a', 0x00}, "", ErrMissingTerminator},
+		{"odd length", []byte{'a', 0x00, 0x00}, "", ErrOddLength},
+		{"data after terminator", []byte{0x00, 0x00, 'a', 0x00}, "", ErrNullCharacter},
+		{"invalid surrogate", []byte{0x00, 0xde, 0x00, 0x00}, "", ErrInvalidSurrogate},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DecodeUTF16Z(tc.inp, UTF16)
+			if !errors.Is(err, tc.wantErr) || got != tc.want {
+				t.Errorf("DecodeUTF16Z() = %q, %v, want %q, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodeUTF16Z(t *testing.T) {
+	tt := []struct {
+		name       string
+		inp        string
+		enc        Encoding
+		want       []byte
+		wantErr    error
+		wantOffset int
+	}{
+		{"empty", "", UCS2, []byte{0x00, 0x00}, nil, 0},
+		{"bmp", "aä", UCS2, []byte{'a', 0x00, 0xe4, 0x00, 0x00, 0x00}, nil, 0},
+		{"replacement character", "�", UCS2, []byte{0xfd, 0xff, 0x00, 0x00}, nil, 0},
+		{"surrogate pair", "a😀", UTF16, []byte{'a', 0x00, 0x3d, 0xd8, 0x00, 0xde, 0x00, 0x00}, nil, 0},
+		{"outside ucs2", "a😀", UCS2, nil, ErrNotUCS2, 1},
+		{"invalid utf-8", "a\xff", UTF16, nil, ErrInvalidUTF8, 1},
+		{"null character", "a\x00", UTF16, nil, ErrNullCharacter, 1},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EncodeUTF16Z(tc.inp, tc.enc)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("EncodeUTF16Z() error = %v, want %v", err, tc.wantErr)
+			}
+			var serr *StringError
+			if errors.As(err, &serr) && serr.Offset != tc.wantOffset {
+				t.Errorf("EncodeUTF16Z() offset = %d, want %d", serr.Offset, tc.wantOffset)
+			}
+			if !bytes.Equal(got, tc.want) {
+				t.Errorf("EncodeUTF16Z() = %v, want %v", got, tc.want)
+			}
+
+			if tc.wantErr == nil {
+				if s, err := DecodeUTF16Z(got, tc.enc); err != nil || s != tc.inp {
+					t.Errorf("DecodeUTF16Z() = %q, %v, want %q", s, err, tc.inp)
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeUTF16(t *testing.T) {
+	got, err := EncodeUTF16("a\x00", UTF16)
+	if want := []byte{'a', 0x00, 0x00, 0x00}; err != nil || !bytes.Equal(got, want) {
+		t.Errorf("EncodeUTF16() = %v, %v, want %v", got, err, want)
+	}
+}
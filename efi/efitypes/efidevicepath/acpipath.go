@@ -15,18 +15,54 @@
 package efidevicepath
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 
 	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
 )
 
 const (
 	_ DevicePathSubType = iota
 
 	ACPISubType
+
+	// ExpandedACPISubType defines the path to an ACPI device
+	// with an extended HID, UID and CID.
+	ExpandedACPISubType
+
+	// ACPIADRSubType defines the path to an ACPI device by its
+	// _ADR method, typically a display output device.
+	ACPIADRSubType
+
+	// NVDIMMSubType defines the path to an NVDIMM device.
+	NVDIMMSubType
+)
+
+// pnpEISAID is the compressed EISA vendor ID of PNP.
+const pnpEISAID = 0x41d0
+
+// Compressed EISA IDs of PCI and PCI Express root bridges.
+const (
+	pciRootEISAID  = 0x0a03<<16 | pnpEISAID
+	pcieRootEISAID = 0x0a08<<16 | pnpEISAID
 )
 
+// acpiAliases maps the device IDs of well-known PNP devices to the
+// names of their text representations.
+var acpiAliases = map[uint32]string{
+	0x0a03: "PciRoot",
+	0x0a08: "PcieRoot",
+	0x0604: "Floppy",
+	0x0301: "Keyboard",
+	0x0501: "Serial",
+	0x0401: "ParallelPort",
+}
+
 func intToEISA(v int) string {
 	vendor := v & 0xffff
 	vendor1 := ((vendor >> 10) & 0x1f) + '@'
@@ -38,6 +74,29 @@ func intToEISA(v int) string {
 	return fmt.Sprintf("%c%c%c%04X", vendor1, vendor2, vendor3, device)
 }
 
+// eisaToInt converts a compressed EISA ID in its text form
+// (e.g. PNP0A03) back to its numeric form.
+func eisaToInt(s string) (uint32, error) {
+	if len(s) != 7 {
+		return 0, ErrInvalidText
+	}
+
+	var vendor uint32
+	for _, c := range s[:3] {
+		if c < '@' || c > '_' {
+			return 0, ErrInvalidText
+		}
+		vendor = vendor<<5 | uint32(c-'@')
+	}
+
+	device, err := strconv.ParseUint(s[3:], 16, 16)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint32(device)<<16 | vendor, nil
+}
+
 // ACPIPath is a ACPI Device Path.
 //
 // <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G14.1009828A>
@@ -52,17 +111,278 @@ func (p *ACPIPath) GetHead() *Head {
 }
 
 func (p *ACPIPath) Text() string {
-	return fmt.Sprintf("ACPI(%s,%d)", intToEISA(int(p.HID)), p.UID)
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *ACPIPath) FormatText(flags TextFlags) string {
+	if p.HID&0xffff != pnpEISAID {
+		return fmt.Sprintf("Acpi(0x%08x,%#x)", p.HID, p.UID)
+	}
+	if name, ok := acpiAliases[p.HID>>16]; ok && flags&AllowShortcuts != 0 {
+		return fmt.Sprintf("%s(%#x)", name, p.UID)
+	}
+	return fmt.Sprintf("Acpi(%s,%#x)", intToEISA(int(p.HID)), p.UID)
 }
 
 func (p *ACPIPath) ReadFrom(r io.Reader) (n int64, err error) {
 	return efireader.ReadFields(r, &p.HID, &p.UID)
 }
 
+func (p *ACPIPath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, ACPIType, ACPISubType, p.HID, p.UID)
+}
+
+// eisa parses the argument at the given position as either a
+// compressed EISA ID like PNP0A03 or a number.
+func (a *textArgs) eisa(i int) uint32 {
+	if v, err := eisaToInt(a.str(i)); err == nil {
+		return v
+	}
+	return a.u32(i)
+}
+
+// parseACPIText parses Acpi(HID,UID).
+func parseACPIText(a *textArgs) DevicePath {
+	return &ACPIPath{HID: a.eisa(0), UID: a.u32(1)}
+}
+
+// acpiAliasTextParser returns a parser for the text representation
+// of the well-known PNP device with the given device ID, i.e.
+// PciRoot(UID).
+func acpiAliasTextParser(device uint32) textParseFn {
+	return func(a *textArgs) DevicePath {
+		return &ACPIPath{HID: device<<16 | pnpEISAID, UID: a.u32(0)}
+	}
+}
+
+// ExpandedACPIPath is an Expanded ACPI Device Path.
+//
+// Section 10.3.3 "ACPI Device Path"
+type ExpandedACPIPath struct {
+	Head
+
+	// HID is the device's PnP hardware ID in compressed EISA
+	// format.
+	HID uint32
+
+	// UID is the unique ID that is required by ACPI if two
+	// devices have the same HID.
+	UID uint32
+
+	// CID is the device's compatible PnP hardware ID in
+	// compressed EISA format.
+	CID uint32
+
+	// HIDSTR is the device's hardware ID as string, used if HID
+	// is zero.
+	HIDSTR string
+
+	// UIDSTR is the unique ID as string, used if UID is zero.
+	UIDSTR string
+
+	// CIDSTR is the device's compatible hardware ID as string,
+	// used if CID is zero.
+	CIDSTR string
+}
+
+func (p *ExpandedACPIPath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *ExpandedACPIPath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+// FormatText returns the text representation of the node.  Nodes
+// with only a UID string are shown as AcpiExp, other PCI root bridges
+// are shown as PciRoot and PcieRoot in DisplayOnly mode.
+func (p *ExpandedACPIPath) FormatText(flags TextFlags) string {
+	if p.HIDSTR == "" && p.CIDSTR == "" && p.UIDSTR != "" {
+		cid := "0"
+		if p.CID != 0 {
+			cid = intToEISA(int(p.CID))
+		}
+		return fmt.Sprintf("AcpiExp(%s,%s,%s)", intToEISA(int(p.HID)), cid, p.UIDSTR)
+	}
+
+	if flags&DisplayOnly != 0 {
+		var name string
+		switch {
+		case p.HID == pciRootEISAID || (p.CID == pciRootEISAID && p.HID != pcieRootEISAID):
+			name = "PciRoot"
+		case p.HID == pcieRootEISAID || p.CID == pcieRootEISAID:
+			name = "PcieRoot"
+		}
+
+		if name != "" {
+			if p.UID == 0 {
+				return fmt.Sprintf("%s(%s)", name, p.UIDSTR)
+			}
+			return fmt.Sprintf("%s(%#x)", name, p.UID)
+		}
+	}
+
+	return fmt.Sprintf(
+		"AcpiEx(%s,%s,%#x,%s,%s,%s)",
+		intToEISA(int(p.HID)),
+		intToEISA(int(p.CID)),
+		p.UID,
+		p.HIDSTR,
+		p.CIDSTR,
+		p.UIDSTR,
+	)
+}
+
+func (p *ExpandedACPIPath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	if err = fr.ReadFields(&p.HID, &p.UID, &p.CID); err != nil {
+		return
+	}
+
+	rest, err := io.ReadAll(fr)
+	if err != nil {
+		return
+	}
+
+	strs := bytes.SplitN(bytes.TrimSuffix(rest, []byte{0}), []byte{0}, 3)
+	for i, dst := range []*string{&p.HIDSTR, &p.UIDSTR, &p.CIDSTR} {
+		if i < len(strs) {
+			*dst = string(strs[i])
+		}
+	}
+	return
+}
+
+func (p *ExpandedACPIPath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		ACPIType,
+		ExpandedACPISubType,
+		p.HID,
+		p.UID,
+		p.CID,
+		efiwriter.TerminateASCII([]byte(p.HIDSTR)),
+		efiwriter.TerminateASCII([]byte(p.UIDSTR)),
+		efiwriter.TerminateASCII([]byte(p.CIDSTR)),
+	)
+}
+
+// parseExpandedACPIText parses AcpiEx(HID,CID,UID,HIDSTR,CIDSTR,UIDSTR).
+func parseExpandedACPIText(a *textArgs) DevicePath {
+	return &ExpandedACPIPath{
+		HID:    a.eisa(0),
+		CID:    a.eisa(1),
+		UID:    a.u32(2),
+		HIDSTR: a.str(3),
+		CIDSTR: a.str(4),
+		UIDSTR: a.str(5),
+	}
+}
+
+// parseExpandedACPIShortText parses AcpiExp(HID,CID,UIDSTR).
+func parseExpandedACPIShortText(a *textArgs) DevicePath {
+	return &ExpandedACPIPath{
+		HID:    a.eisa(0),
+		CID:    a.eisa(1),
+		UIDSTR: a.str(2),
+	}
+}
+
+// ACPIADRPath is an ACPI _ADR Device Path, identifying one or more
+// display output devices.
+//
+// Section 10.3.3.1 "ACPI _ADR Device Path"
+type ACPIADRPath struct {
+	Head
+
+	// ADR contains the _ADR values of the display output
+	// devices.
+	ADR []uint32
+}
+
+func (p *ACPIADRPath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *ACPIADRPath) Text() string {
+	adr := make([]string, len(p.ADR))
+	for i, v := range p.ADR {
+		adr[i] = fmt.Sprintf("%#x", v)
+	}
+	return fmt.Sprintf("AcpiAdr(%s)", strings.Join(adr, ","))
+}
+
+func (p *ACPIADRPath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	p.ADR = nil
+	for {
+		var v uint32
+		if err = fr.ReadFields(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return
+		}
+		p.ADR = append(p.ADR, v)
+	}
+}
+
+func (p *ACPIADRPath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, ACPIType, ACPIADRSubType, p.ADR)
+}
+
+// parseACPIADRText parses AcpiAdr(DisplayDevice[,DisplayDevice]...).
+func parseACPIADRText(a *textArgs) DevicePath {
+	p := &ACPIADRPath{}
+	for i := range a.args {
+		p.ADR = append(p.ADR, a.u32(i))
+	}
+	return p
+}
+
+// NVDIMMPath is an ACPI NVDIMM Device Path.
+//
+// Section 10.3.3.2 "NVDIMM Device Path"
+type NVDIMMPath struct {
+	Head
+
+	// NFITDeviceHandle is the NFIT device handle of the NVDIMM.
+	NFITDeviceHandle uint32
+}
+
+func (p *NVDIMMPath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *NVDIMMPath) Text() string {
+	return fmt.Sprintf("NvdimmHandle(%#x)", p.NFITDeviceHandle)
+}
+
+func (p *NVDIMMPath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.NFITDeviceHandle)
+}
+
+func (p *NVDIMMPath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, ACPIType, NVDIMMSubType, p.NFITDeviceHandle)
+}
+
+// parseNVDIMMText parses NvdimmHandle(NFITDeviceHandle).
+func parseNVDIMMText(a *textArgs) DevicePath {
+	return &NVDIMMPath{NFITDeviceHandle: a.u32(0)}
+}
+
 func ParseACPIDevicePath(f io.Reader, h Head) (p DevicePath, err error) {
 	switch h.SubType {
 	case ACPISubType:
 		p = &ACPIPath{Head: h}
+	case ExpandedACPISubType:
+		p = &ExpandedACPIPath{Head: h}
+	case ACPIADRSubType:
+		p = &ACPIADRPath{Head: h}
+	case NVDIMMSubType:
+		p = &NVDIMMPath{Head: h}
 	default:
 		p = &UnrecognizedDevicePath{Head: h}
 	}
@@ -72,3 +392,9 @@ func ParseACPIDevicePath(f io.Reader, h Head) (p DevicePath, err error) {
 	}
 	return
 }
+
+func init() {
+	for device, name := range acpiAliases {
+		textParsers[name] = acpiAliasTextParser(device)
+	}
+}
@@ -15,6 +15,7 @@
 package efidevicepath
 
 import (
+	"bytes"
 	"testing"
 )
 
@@ -28,9 +29,9 @@ func TestACPIDevicePath_Notation(t *testing.T) {
 		fields fields
 		want   string
 	}{
-		{"PNP0A03,0", fields{0x0A0341D0, 0}, "ACPI(PNP0A03,0)"},
-		{"PNP0A03,1", fields{0x0A0341D0, 1}, "ACPI(PNP0A03,1)"},
-		{"PNP0A03,2", fields{0x0A0341D0, 2}, "ACPI(PNP0A03,2)"},
+		{"PNP0A03,0", fields{0x0A0341D0, 0}, "PciRoot(0x0)"},
+		{"PNP0A03,1", fields{0x0A0341D0, 1}, "PciRoot(0x1)"},
+		{"PNP0A03,2", fields{0x0A0341D0, 2}, "PciRoot(0x2)"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -44,3 +45,83 @@ func TestACPIDevicePath_Notation(t *testing.T) {
 		})
 	}
 }
+
+func TestACPIDevicePath(t *testing.T) {
+	runNodeTests(t, []nodeTestCase{
+		{
+			"serial",
+			&ACPIPath{HID: 0x050141d0, UID: 1},
+			"Serial(0x1)",
+			"02010c00 d0410105 01000000",
+		},
+		{
+			"pnp without alias",
+			&ACPIPath{HID: 0x030341d0},
+			"Acpi(PNP0303,0x0)",
+			"02010c00 d0410303 00000000",
+		},
+		{
+			"non-pnp",
+			&ACPIPath{HID: 0x00001234},
+			"Acpi(0x00001234,0x0)",
+			"02010c00 34120000 00000000",
+		},
+		{
+			"expanded",
+			&ExpandedACPIPath{HID: 0x0a0341d0, UID: 1, HIDSTR: "HID"},
+			"AcpiEx(PNP0A03,@@@0000,0x1,HID,,)",
+			"02021600 d041030a 01000000 00000000 48494400 00 00",
+		},
+		{
+			"expanded short form",
+			&ExpandedACPIPath{HID: 0x0a0841d0, CID: 0x0a0341d0, UIDSTR: "abc"},
+			"AcpiExp(PNP0A08,PNP0A03,abc)",
+			"02021600 d041080a 00000000 d041030a 00 61626300 00",
+		},
+		{
+			"adr",
+			&ACPIADRPath{ADR: []uint32{0x80010100, 0x80010200}},
+			"AcpiAdr(0x80010100,0x80010200)",
+			"02030c00 00010180 00020180",
+		},
+		{
+			"nvdimm",
+			&NVDIMMPath{NFITDeviceHandle: 1},
+			"NvdimmHandle(0x1)",
+			"02040800 01000000",
+		},
+	})
+}
+
+func TestParseText_ACPIAliases(t *testing.T) {
+	tt := []struct {
+		inp  string
+		want string
+	}{
+		{"PciRoot(0)", "02010c00 d041030a 00000000"},
+		{"PcieRoot(1)", "02010c00 d041080a 01000000"},
+		{"Floppy(0)", "02010c00 d0410406 00000000"},
+		{"Keyboard(0)", "02010c00 d0410103 00000000"},
+		{"ParallelPort(0)", "02010c00 d0410104 00000000"},
+		{"Acpi(PNP0A03,0)", "02010c00 d041030a 00000000"},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.inp, func(t *testing.T) {
+			p, err := ParseNodeText(tc.inp)
+			if err != nil {
+				t.Fatalf("ParseNodeText() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if _, err := p.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+
+			want := mustDecodeHex(t, tc.want)
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+			}
+		})
+	}
+}
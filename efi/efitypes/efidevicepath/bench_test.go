@@ -0,0 +1,94 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"testing"
+)
+
+// benchDevicePath returns a typical boot entry Device Path consisting
+// of the path to a NVMe disk, a partition and a file path.
+func benchDevicePath(b *testing.B) []byte {
+	b.Helper()
+	p, err := ParseText(`PciRoot(0x0)/Pci(0x1d,0x0)/NVMe(0x1,00-25-38-5b-71-b0-a1-c2)/` +
+		`HD(1,GPT,3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,0x800,0x32000)/File(\EFI\Microsoft\Boot\bootmgfw.efi)`)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkDevicePaths_ReadFrom(b *testing.B) {
+	inp := benchDevicePath(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(inp)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var p DevicePaths
+		if _, err := p.ReadFrom(bytes.NewReader(inp)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	inp := benchDevicePath(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(inp)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(inp, DecodeOptions{Strict: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFileName(b *testing.B) {
+	inp := benchDevicePath(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(inp)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, err := Decode(inp, DecodeOptions{})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if FileName(p) == "" {
+			b.Fatal("missing file name")
+		}
+	}
+}
+
+func BenchmarkDevicePathView_FileName(b *testing.B) {
+	inp := benchDevicePath(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(inp)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if DevicePathView(inp).FileName() == "" {
+			b.Fatal("missing file name")
+		}
+	}
+}
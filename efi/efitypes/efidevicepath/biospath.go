@@ -17,8 +17,10 @@ package efidevicepath
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
 )
 
 const (
@@ -27,6 +29,10 @@ const (
 	BIOSBootSpecSubType
 )
 
+// bbsDeviceTypeNames contains the names of the device types defined
+// by the BIOS Boot Specification indexed by their number.
+var bbsDeviceTypeNames = []string{"", "Floppy", "HD", "CDROM", "PCMCIA", "USB", "Network"}
+
 // BIOSBootSpecPath is used to describe the booting of non-EFI-aware operating systems.
 //
 // <https://uefi.org/specs/UEFI/2.9_A/10_Protocols_Device_Path_Protocol.html#bios-boot-specification-device-path-1>
@@ -43,7 +49,7 @@ type BIOSBootSpecPath struct {
 
 	// Description is a zero-terminated string that describes
 	// this device to a user.
-	Description []byte
+	Description []byte `efijson:"asciiz"`
 }
 
 func (p *BIOSBootSpecPath) GetHead() *Head {
@@ -51,12 +57,24 @@ func (p *BIOSBootSpecPath) GetHead() *Head {
 }
 
 func (p *BIOSBootSpecPath) Text() string {
-	return fmt.Sprintf(
-		"BBS(%d,%s,%x)",
-		p.DeviceType,
-		formatString(efireader.ASCIIZBytesToString(p.Description)),
-		p.StatusFlag,
-	)
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *BIOSBootSpecPath) FormatText(flags TextFlags) string {
+	var b strings.Builder
+
+	if int(p.DeviceType) < len(bbsDeviceTypeNames) && p.DeviceType != 0 {
+		b.WriteString("BBS(" + bbsDeviceTypeNames[p.DeviceType])
+	} else {
+		fmt.Fprintf(&b, "BBS(%#x", p.DeviceType)
+	}
+	b.WriteString("," + efireader.ASCIIZBytesToString(p.Description))
+
+	if flags&DisplayOnly == 0 {
+		fmt.Fprintf(&b, ",%#x", p.StatusFlag)
+	}
+	b.WriteString(")")
+	return b.String()
 }
 
 func (p *BIOSBootSpecPath) ReadFrom(r io.Reader) (n int64, err error) {
@@ -72,6 +90,26 @@ func (p *BIOSBootSpecPath) ReadFrom(r io.Reader) (n int64, err error) {
 	return
 }
 
+func (p *BIOSBootSpecPath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		BIOSBootType,
+		BIOSBootSpecSubType,
+		p.DeviceType,
+		p.StatusFlag,
+		efiwriter.TerminateASCII(p.Description),
+	)
+}
+
+// parseBIOSBootSpecText parses BBS(Type,Id,Flags).
+func parseBIOSBootSpecText(a *textArgs) DevicePath {
+	return &BIOSBootSpecPath{
+		DeviceType:  uint16(a.enum(0, 16, bbsDeviceTypeNames...)),
+		Description: efiwriter.TerminateASCII([]byte(a.str(1))),
+		StatusFlag:  a.u16(2),
+	}
+}
+
 func ParseBIOSDevicePath(f io.Reader, h Head) (p DevicePath, err error) {
 	switch h.SubType {
 	case BIOSBootSpecSubType:
@@ -0,0 +1,187 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNodeTooShort  = errors.New("node length shorter than its header")
+	ErrNodeTruncated = errors.New("node exceeds the available data")
+	ErrNodeTooLong   = errors.New("node has unused trailing bytes")
+	ErrMissingEnd    = errors.New("missing end of device path node")
+	ErrTrailingData  = errors.New("trailing data after end of device path")
+	ErrTooManyNodes  = errors.New("too many nodes")
+)
+
+// DefaultMaxNodes is the maximum number of nodes in a single Device
+// Path accepted by default.
+const DefaultMaxNodes = 1024
+
+// ParseError describes a malformed Device Path.
+type ParseError struct {
+	// Offset is the offset in bytes of the malformed node, or of
+	// the position the error was detected at, within the input.
+	Offset int64
+
+	// Head is the header of the malformed node.  It is zero if
+	// the error is not related to a single node.
+	Head Head
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	if e.Head == (Head{}) {
+		return fmt.Sprintf("efi/devicepath: offset %d: %v", e.Offset, e.Err)
+	}
+	return fmt.Sprintf("efi/devicepath: offset %d: type %d-%d: %v", e.Offset, e.Head.Type, e.Head.SubType, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeOptions controls how Decode deals with malformed input.
+type DecodeOptions struct {
+	// Strict rejects any input not being a well-formed Device
+	// Path. Nodes need to be exactly as long as their contents,
+	// the Device Path has to be terminated by an End Entire
+	// Device Path node and no data may follow it.
+	//
+	// Otherwise, Decode recovers as many nodes as possible.  Nodes
+	// with a contents that can not be decoded or with unused
+	// trailing bytes are returned as UnrecognizedDevicePath, data
+	// following the Device Path is ignored and decoding stops at
+	// the first node which is not fully contained in the input,
+	// without returning an error.
+	Strict bool
+
+	// MaxNodes limits the number of nodes, DefaultMaxNodes is
+	// used if zero.  Exceeding the limit is an error in strict
+	// mode and stops decoding otherwise.
+	MaxNodes int
+}
+
+// Decode decodes the binary representation of a Device Path from b,
+// which may hold untrusted data. All errors returned are of the
+// type *ParseError.
+func Decode(b []byte, opts DecodeOptions) (DevicePaths, error) {
+	d := newDecoder(b, opts)
+	if ok, err := d.path(); !ok || err != nil {
+		return d.out, err
+	}
+	if d.off < len(b) {
+		return d.out, d.fail(d.off, Head{}, ErrTrailingData)
+	}
+	return d.out, nil
+}
+
+// DecodeList decodes a packed array of Device Paths from b, each
+// terminated by an End Entire Device Path node, like the
+// FilePathList of a load option.  The nodes of all Device Paths are
+// returned in a single slice.  Options are applied as by Decode,
+// with MaxNodes limiting the total number of nodes and the last
+// Device Path having to end exactly at the end of b in strict mode.
+func DecodeList(b []byte, opts DecodeOptions) (DevicePaths, error) {
+	d := newDecoder(b, opts)
+	for d.off < len(b) || len(d.out) == 0 {
+		if ok, err := d.path(); !ok || err != nil {
+			return d.out, err
+		}
+	}
+	return d.out, nil
+}
+
+// decoder holds the state of Decode and DecodeList.
+type decoder struct {
+	b        []byte
+	off      int
+	out      DevicePaths
+	strict   bool
+	maxNodes int
+}
+
+func newDecoder(b []byte, opts DecodeOptions) *decoder {
+	maxNodes := opts.MaxNodes
+	if maxNodes <= 0 {
+		maxNodes = DefaultMaxNodes
+	}
+	return &decoder{b: b, strict: opts.Strict, maxNodes: maxNodes}
+}
+
+// fail reports the given error in strict mode and stops decoding
+// with the nodes decoded so far otherwise.
+func (d *decoder) fail(off int, h Head, err error) error {
+	if !d.strict {
+		return nil
+	}
+	return &ParseError{Offset: int64(off), Head: h, Err: err}
+}
+
+// path decodes a single Device Path up to and including its End
+// Entire Device Path node.  It reports whether decoding may
+// continue.
+func (d *decoder) path() (bool, error) {
+	b := d.b
+	for {
+		off := d.off
+		switch {
+		case off == len(b):
+			return false, d.fail(off, Head{}, ErrMissingEnd)
+		case len(d.out) == d.maxNodes:
+			return false, d.fail(off, Head{}, ErrTooManyNodes)
+		case len(b)-off < 4:
+			return false, d.fail(off, Head{}, ErrNodeTruncated)
+		}
+
+		head := Head{
+			Type:    DevicePathType(b[off]),
+			SubType: DevicePathSubType(b[off+1]),
+			Length:  binary.LittleEndian.Uint16(b[off+2:]),
+		}
+		switch {
+		case head.Length < 4:
+			return false, d.fail(off, head, ErrNodeTooShort)
+		case int(head.Length) > len(b)-off:
+			return false, d.fail(off, head, ErrNodeTruncated)
+		}
+
+		body := b[off+4 : off+int(head.Length)]
+		r := bytes.NewReader(body)
+
+		n, err := parseNode(r, head)
+		switch {
+		case err != nil && d.strict:
+			return false, &ParseError{Offset: int64(off), Head: head, Err: err}
+		case r.Len() > 0 && d.strict:
+			return false, &ParseError{Offset: int64(off), Head: head, Err: ErrNodeTooLong}
+		case err != nil || r.Len() > 0:
+			n = &UnrecognizedDevicePath{Head: head, Data: append([]byte(nil), body...)}
+		}
+
+		d.out = append(d.out, n)
+		d.off += int(head.Length)
+
+		if head.Is(EndOfPathType, EndEntireSubType) {
+			return true, nil
+		}
+	}
+}
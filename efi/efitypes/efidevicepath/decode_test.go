@@ -0,0 +1,165 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tt := []struct {
+		name       string
+		inp        string
+		wantErr    error
+		wantOffset int64
+		lenient    []string
+	}{
+		{"valid", "010106000001 7fff0400", nil, 0, []string{"Pci(0x1,0x0)"}},
+		{"empty", "", ErrMissingEnd, 0, []string{""}},
+		{"missing end", "010106000001", ErrMissingEnd, 6, []string{"Pci(0x1,0x0)"}},
+		{"short length", "010106000001 01010200 7fff0400", ErrNodeTooShort, 6, []string{"Pci(0x1,0x0)"}},
+		{"zero length", "01010000 7fff0400", ErrNodeTooShort, 0, []string{""}},
+		{"truncated head", "010106000001 7fff", ErrNodeTruncated, 6, []string{"Pci(0x1,0x0)"}},
+		{"truncated node", "010106000001 01010800000100", ErrNodeTruncated, 6, []string{"Pci(0x1,0x0)"}},
+		{"overlong node", "01010800000100aa 7fff0400", ErrNodeTooLong, 0, []string{"HardwarePath(1,000100aa)"}},
+		{"undecodable node", "010105000001 7fff0400", io.EOF, 0, []string{"HardwarePath(1,00)"}},
+		{"trailing data", "010106000001 7fff0400 aa", ErrTrailingData, 10, []string{"Pci(0x1,0x0)"}},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inp := mustDecodeHex(t, tc.inp)
+
+			_, err := Decode(inp, DecodeOptions{Strict: true})
+			if tc.wantErr == nil {
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+			} else {
+				var perr *ParseError
+				if !errors.As(err, &perr) {
+					t.Fatalf("Decode() error = %v, want *ParseError", err)
+				}
+				if perr.Offset != tc.wantOffset {
+					t.Errorf("Decode() error offset = %d, want %d", perr.Offset, tc.wantOffset)
+				}
+				if !errors.Is(err, tc.wantErr) {
+					t.Errorf("Decode() error = %v, want %v", err, tc.wantErr)
+				}
+			}
+
+			p, err := Decode(inp, DecodeOptions{})
+			if err != nil {
+				t.Fatalf("Decode() lenient error = %v", err)
+			}
+			if got := strings.Join(p.AllText(), ","); got != strings.Join(tc.lenient, ",") {
+				t.Errorf("Decode() lenient = %v, want %v", got, tc.lenient)
+			}
+		})
+	}
+}
+
+func TestDecode_MaxNodes(t *testing.T) {
+	inp := mustDecodeHex(t, strings.Repeat("010106000001", 3)+"7fff0400")
+
+	_, err := Decode(inp, DecodeOptions{Strict: true, MaxNodes: 3})
+	if !errors.Is(err, ErrTooManyNodes) {
+		t.Errorf("Decode() error = %v, want %v", err, ErrTooManyNodes)
+	}
+
+	p, err := Decode(inp, DecodeOptions{MaxNodes: 2})
+	if err != nil || len(p) != 2 {
+		t.Errorf("Decode() = %d nodes, %v, want 2 nodes", len(p), err)
+	}
+
+	if _, err := Decode(inp, DecodeOptions{Strict: true}); err != nil {
+		t.Errorf("Decode() error = %v", err)
+	}
+}
+
+func TestDecodeList(t *testing.T) {
+	inp := mustDecodeHex(t, "010106000001 7fff0400 040408005c000000 7fff0400")
+
+	p, err := DecodeList(inp, DecodeOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("DecodeList() error = %v", err)
+	}
+	if len(p) != 4 {
+		t.Errorf("DecodeList() = %d nodes, want 4", len(p))
+	}
+
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil || !bytes.Equal(buf.Bytes(), inp) {
+		t.Errorf("WriteTo() = %x, %v, want %x", buf.Bytes(), err, inp)
+	}
+
+	_, err = DecodeList(inp[:len(inp)-2], DecodeOptions{Strict: true})
+	if !errors.Is(err, ErrNodeTruncated) {
+		t.Errorf("DecodeList() error = %v, want %v", err, ErrNodeTruncated)
+	}
+
+	p, err = DecodeList(inp[:len(inp)-2], DecodeOptions{})
+	if err != nil || len(p) != 3 {
+		t.Errorf("DecodeList() lenient = %d nodes, %v, want 3 nodes", len(p), err)
+	}
+}
+
+func TestDevicePaths_ReadFromShortNode(t *testing.T) {
+	inp := mustDecodeHex(t, "010106000001 01010200 7fff0400")
+
+	var p DevicePaths
+	_, err := p.ReadFrom(bytes.NewReader(inp))
+
+	var perr *ParseError
+	if !errors.As(err, &perr) || !errors.Is(err, ErrNodeTooShort) {
+		t.Fatalf("ReadFrom() error = %v, want %v", err, ErrNodeTooShort)
+	}
+	if perr.Offset != 6 {
+		t.Errorf("ReadFrom() error offset = %d, want 6", perr.Offset)
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, s := range []string{
+		"04012a00 01000000 0008000000000000 0020030000000000 ffffffffffffffffffffffffffffffff 0202" +
+			"04042a004500460049005c004c0049004e00550058005c0047005200550042002e0045004600490000007fff0400",
+		"050109000500000000 7fff0400",
+		"0101060000017f010400 02010c00d041030a000000007fff0400",
+	} {
+		b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		for _, strict := range []bool{false, true} {
+			p, err := Decode(b, DecodeOptions{Strict: strict})
+			if err != nil {
+				continue
+			}
+
+			var buf bytes.Buffer
+			_, _ = p.WriteTo(&buf)
+			_ = p.AllText()
+		}
+	})
+}
@@ -15,14 +15,18 @@
 package efidevicepath
 
 import (
+	"bytes"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
-	"strings"
-
-	"github.com/0x5a17ed/itkit"
-	"github.com/0x5a17ed/itkit/iters/sliceit"
 
 	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
+)
+
+var (
+	ErrNodeTooLarge = errors.New("node exceeds maximum length")
 )
 
 // DevicePathType is used in Head to distinguish individual
@@ -92,104 +96,196 @@ func (h *Head) Is(t DevicePathType, st DevicePathSubType) bool {
 }
 
 type DevicePath interface {
+	// ReaderFrom reads the node body following the Head.
 	io.ReaderFrom
 
+	// WriterTo writes the complete node including its Head. The
+	// Length field of the Head is computed from the encoded body.
+	io.WriterTo
+
 	GetHead() *Head
 
-	// Text returns a text representation of a Device Path.
+	// Text returns a text representation of a Device Path.  It
+	// matches NodeText with AllowShortcuts, except for file paths
+	// which are wrapped in File(...) like efibootmgr does.
 	//
 	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G14.1012867>
 	Text() string
 }
 
+// TextFlags selects the text representation of Device Path nodes in
+// the same way as the DisplayOnly and AllowShortcuts parameters of
+// the ConvertDevicePathToText function of the
+// EFI_DEVICE_PATH_TO_TEXT_PROTOCOL.
+type TextFlags uint8
+
+const (
+	// DisplayOnly selects the shorter display representation,
+	// omitting information needed only to convert the text back
+	// into a Device Path.
+	DisplayOnly TextFlags = 1 << iota
+
+	// AllowShortcuts enables the shortcut forms of nodes, e.g.
+	// PciRoot(0x0) instead of Acpi(PNP0A03,0x0).
+	AllowShortcuts
+)
+
+// TextFormatter is implemented by Device Path nodes whose text
+// representation depends on TextFlags.
+type TextFormatter interface {
+	// FormatText returns the text representation of the node
+	// selected by flags.
+	FormatText(flags TextFlags) string
+}
+
+// NodeText returns the text representation of d selected by flags.
+// Nodes not implementing TextFormatter are represented by their Text
+// method.
+func NodeText(d DevicePath, flags TextFlags) string {
+	if f, ok := d.(TextFormatter); ok {
+		return f.FormatText(flags)
+	}
+	return d.Text()
+}
+
 // DevicePaths defines the programmatic path to a device.
 //
 // <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G14.1009325>
 type DevicePaths []DevicePath
 
-func text(iter itkit.Iterator[DevicePath]) (out string, last DevicePath) {
-	var b strings.Builder
-	if !iter.Next() {
-		return
-	}
+// writeNode writes a complete Device Path node of the given type and
+// subtype to w. The fields making up the node body are encoded in
+// little endian byte order.
+func writeNode(w io.Writer, t DevicePathType, st DevicePathSubType, fields ...any) (n int64, err error) {
+	var b efiwriter.Buffer
+	b.Write([]byte{byte(t), byte(st)})
+	length := b.ReserveUint16()
 
-	last = iter.Value()
-	if last.GetHead().Type == EndOfPathType {
-		return
+	if _, err = efiwriter.WriteFields(&b, fields...); err != nil {
+		return 0, err
 	}
-	b.WriteString(iter.Value().Text())
-
-	for iter.Next() {
-		last = iter.Value()
-		if last.GetHead().Type == EndOfPathType {
-			break
-		}
-		b.WriteString("/")
-		b.WriteString(iter.Value().Text())
+	if err = length.SetLength(0); err != nil {
+		return 0, fmt.Errorf("type %d-%d: %w", t, st, ErrNodeTooLarge)
 	}
-	out = b.String()
-	return
+
+	return b.WriteTo(w)
 }
 
 func (p *DevicePaths) AllText() (out []string) {
+	return p.allText(DevicePath.Text)
+}
+
+// FormatAllText returns the text representation of each Device Path
+// instance selected by flags, in the same way as
+// ConvertDevicePathToText would.
+func (p *DevicePaths) FormatAllText(flags TextFlags) []string {
+	return p.allText(func(d DevicePath) string { return NodeText(d, flags) })
+}
+
+func (p *DevicePaths) allText(fn func(DevicePath) string) (out []string) {
 	if p != nil {
-		iter := sliceit.In(*p)
-		for {
-			item, last := text(iter)
-			out = append(out, item)
-
-			if last == nil || last.GetHead().Is(EndOfPathType, 255) {
-				break
-			}
+		for _, inst := range p.Instances() {
+			out = append(out, inst.text(fn))
 		}
 	}
 	return
 }
 
-func (p *DevicePaths) ReadFrom(r io.Reader) (n int64, err error) {
-	var quit bool
+// parseNode parses the body of a single Device Path node described
+// by the given Head.
+func parseNode(r io.Reader, head Head) (d DevicePath, err error) {
+	switch head.Type {
+	case HardwareType:
+		d, err = ParseHardwareDevicePath(r, head)
+	case ACPIType:
+		d, err = ParseACPIDevicePath(r, head)
+	case MessagingType:
+		d, err = ParseMessagingDevicePath(r, head)
+	case MediaType:
+		d, err = ParseMediaDevicePath(r, head)
+	case BIOSBootType:
+		d, err = ParseBIOSDevicePath(r, head)
+	case EndOfPathType:
+		d = &EndOfPath{head}
+	default:
+		d, err = ParseUnrecognizedDevicePath(r, head)
+	}
+	return
+}
 
+// ReadFrom reads Device Path nodes from r up to and including the End
+// Entire Device Path node and appends them to p. Unused bytes of
+// nodes are skipped.  Use Decode to parse untrusted input.
+func (p *DevicePaths) ReadFrom(r io.Reader) (n int64, err error) {
 	fr := efireader.NewFieldReader(r, &n)
-	for !quit {
-		sfr := efireader.NewFieldReader(fr, nil)
 
-		var head Head
-		if err = sfr.ReadFields(&head); err != nil {
-			return fr.Offset(), fmt.Errorf("head: %w", err)
+	// Node bodies are read in one go and decoded from memory, the
+	// buffer is reused as decoders copy whatever they keep.
+	var head [4]byte
+	var body []byte
+	for nodes := 0; ; nodes++ {
+		if nodes == DefaultMaxNodes {
+			return n, &ParseError{Offset: n, Err: ErrTooManyNodes}
 		}
 
-		bodyReader := io.LimitReader(sfr, int64(head.Length-4))
+		if _, err = io.ReadFull(fr, head[:]); err != nil {
+			return n, fmt.Errorf("head: %w", err)
+		}
+		h := Head{
+			Type:    DevicePathType(head[0]),
+			SubType: DevicePathSubType(head[1]),
+			Length:  binary.LittleEndian.Uint16(head[2:]),
+		}
+		if h.Length < 4 {
+			return n, &ParseError{Offset: n - 4, Head: h, Err: ErrNodeTooShort}
+		}
 
-		var d DevicePath
-		switch head.Type {
-		case HardwareType:
-			d, err = ParseHardwareDevicePath(bodyReader, head)
-		case ACPIType:
-			d, err = ParseACPIDevicePath(bodyReader, head)
-		case MessagingType:
-			d, err = ParseMessagingDevicePath(bodyReader, head)
-		case MediaType:
-			d, err = ParseMediaDevicePath(bodyReader, head)
-		case BIOSBootType:
-			d, err = ParseBIOSDevicePath(bodyReader, head)
-		case EndOfPathType:
-			d = &EndOfPath{head}
-			quit = head.SubType == EndEntireSubType
-		default:
-			d, err = ParseUnrecognizedDevicePath(bodyReader, head)
+		if l := int(h.Length) - 4; cap(body) < l {
+			body = make([]byte, l)
+		} else {
+			body = body[:l]
+		}
+		if _, err = io.ReadFull(fr, body); err != nil {
+			return n, fmt.Errorf("body: %w", err)
 		}
 
-		if err != nil {
+		br := bytes.NewReader(body)
+		var d DevicePath
+		if d, err = parseNode(br, h); err != nil {
 			return
 		}
+		if br.Len() > 0 {
+			// Keep the unused trailing bytes, so that the node is
+			// written back unchanged.
+			d = &UnrecognizedDevicePath{Head: h, Data: append([]byte(nil), body...)}
+		}
 		*p = append(*p, d)
 
-		if left := int64(head.Length) - sfr.Offset(); left > 0 {
-			_, err = io.CopyN(io.Discard, sfr, left)
-			if err != nil {
-				return fr.Offset(), fmt.Errorf("copy: %w", err)
-			}
+		if h.Is(EndOfPathType, EndEntireSubType) {
+			return
+		}
+	}
+}
+
+// WriteTo writes the binary representation of all Device Path nodes
+// to w.  An End Entire Device Path node is appended if the last node
+// does not terminate the Device Path already.
+func (p *DevicePaths) WriteTo(w io.Writer) (n int64, err error) {
+	var st DevicePathSubType
+	for i, d := range *p {
+		var m int64
+		m, err = d.WriteTo(w)
+		n += m
+		if err != nil {
+			return n, fmt.Errorf("node #%d: %w", i, err)
 		}
+		st, _ = endSubType(d)
+	}
+
+	if st != EndEntireSubType {
+		var m int64
+		m, err = (&EndOfPath{}).WriteTo(w)
+		n += m
 	}
 	return
 }
@@ -0,0 +1,407 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+type nodeTestCase struct {
+	name string
+	node DevicePath
+	text string
+	bin  string
+}
+
+// runNodeTests checks the text representation and binary encoding of
+// Device Path nodes and ensures both, as well as the JSON
+// representation, can be parsed back into an equivalent node.
+func runNodeTests(t *testing.T, tt []nodeTestCase) {
+	t.Helper()
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.node.Text(); got != tc.text {
+				t.Errorf("Text() = %v, want %v", got, tc.text)
+			}
+
+			want := mustDecodeHex(t, tc.bin)
+
+			var buf bytes.Buffer
+			if _, err := tc.node.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+			}
+
+			var p DevicePaths
+			if _, err := p.ReadFrom(bytes.NewReader(append(want, 0x7f, 0xff, 0x04, 0x00))); err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if got := p[0].Text(); got != tc.text {
+				t.Errorf("ReadFrom() Text() = %v, want %v", got, tc.text)
+			}
+
+			parsed, err := ParseNodeText(tc.text)
+			if err != nil {
+				t.Fatalf("ParseNodeText() error = %v", err)
+			}
+
+			buf.Reset()
+			if _, err := parsed.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("ParseNodeText() WriteTo() = %x, want %x", buf.Bytes(), want)
+			}
+
+			b, err := MarshalNodeJSON(p[0])
+			if err != nil {
+				t.Fatalf("MarshalNodeJSON() error = %v", err)
+			}
+			decoded, err := UnmarshalNodeJSON(b)
+			if err != nil {
+				t.Fatalf("UnmarshalNodeJSON(%s) error = %v", b, err)
+			}
+
+			buf.Reset()
+			if _, err := decoded.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("UnmarshalNodeJSON(%s) WriteTo() = %x, want %x", b, buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestDevicePaths_RoundTrip(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+		want []string
+	}{
+		{
+			"hd and file",
+			"04012a00 01000000 0008000000000000 0020030000000000 ffffffffffffffffffffffffffffffff 0202" +
+				"04042a004500460049005c004c0049004e00550058005c0047005200550042002e0045004600490000007fff0400",
+			[]string{`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/File(EFI\LINUX\GRUB.EFI)`},
+		},
+		{
+			"bbs",
+			"050109000500000000 7fff0400",
+			[]string{`BBS(USB,,0x0)`},
+		},
+		{
+			"unrecognized",
+			"8001090001234567897fff0400",
+			[]string{"Path(128,1,0123456789)"},
+		},
+		{
+			"multiple instances",
+			"0101060000017f010400 02010c00d041030a000000007fff0400",
+			[]string{"Pci(0x1,0x0)", "PciRoot(0x0)"},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inp := mustDecodeHex(t, tc.inp)
+
+			var p DevicePaths
+			n, err := p.ReadFrom(bytes.NewReader(inp))
+			if err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if n != int64(len(inp)) {
+				t.Errorf("ReadFrom() n = %d, want %d", n, len(inp))
+			}
+			if got := strings.Join(p.AllText(), ","); got != strings.Join(tc.want, ",") {
+				t.Errorf("AllText() = %v, want %v", got, tc.want)
+			}
+
+			var buf bytes.Buffer
+			n, err = p.WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if n != int64(buf.Len()) {
+				t.Errorf("WriteTo() n = %d, want %d", n, buf.Len())
+			}
+			if !bytes.Equal(buf.Bytes(), inp) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), inp)
+			}
+		})
+	}
+}
+
+func TestDevicePath_WriteTo(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  DevicePath
+		want string
+	}{
+		{
+			"pci",
+			&PCIDevicePath{Function: 2, Device: 0x1f},
+			"01010600021f",
+		},
+		{
+			"acpi",
+			&ACPIPath{HID: 0x0A0341D0, UID: 1},
+			"02010c00d041030a01000000",
+		},
+		{
+			"cdrom",
+			&CDROMDevicePath{BootEntry: 1, PartitionStartRBA: 0x10, PartitionSize: 0x20},
+			"04021800010000001000000000000000 2000000000000000",
+		},
+		{
+			"vendor media",
+			&VendorMediaDevicePath{
+				VendorGUID:        efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7"),
+				VendorDefinedData: []byte{0xaa, 0xbb},
+			},
+			"04031600 3f9fd93c2b4beb43ac29f0890a4772b7 aabb",
+		},
+		{
+			"file path without terminator",
+			&FilePathDevicePath{PathName: []byte{'a', 0}},
+			"040408006100 0000",
+		},
+		{
+			"bbs without terminator",
+			&BIOSBootSpecPath{DeviceType: 2, StatusFlag: 1, Description: []byte("a")},
+			"0501 0a00 0200 0100 6100",
+		},
+		{
+			"end of path",
+			&EndOfPath{},
+			"7fff0400",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			want := mustDecodeHex(t, tc.want)
+
+			var buf bytes.Buffer
+			n, err := tc.inp.WriteTo(&buf)
+			if err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if n != int64(len(want)) {
+				t.Errorf("WriteTo() n = %d, want %d", n, len(want))
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestDevicePaths_WriteToAppendsEnd(t *testing.T) {
+	p := DevicePaths{&PCIDevicePath{Function: 0, Device: 1}}
+
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+
+	want := mustDecodeHex(t, "010106000001 7fff0400")
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestDevicePaths_ReadFromMaxNodes(t *testing.T) {
+	node := mustDecodeHex(t, "010106000001")
+	end := mustDecodeHex(t, "7fff0400")
+
+	// The limit applies to each Device Path read, not to the nodes
+	// already held by p.
+	p := make(DevicePaths, DefaultMaxNodes)
+	inp := append(bytes.Repeat(node, DefaultMaxNodes-1), end...)
+	if _, err := p.ReadFrom(bytes.NewReader(inp)); err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+	if len(p) != 2*DefaultMaxNodes {
+		t.Errorf("ReadFrom() = %d nodes, want %d", len(p), 2*DefaultMaxNodes)
+	}
+
+	p = nil
+	inp = append(bytes.Repeat(node, DefaultMaxNodes), end...)
+	if _, err := p.ReadFrom(bytes.NewReader(inp)); !errors.Is(err, ErrTooManyNodes) {
+		t.Errorf("ReadFrom() error = %v, want %v", err, ErrTooManyNodes)
+	}
+}
+
+func TestDevicePaths_ReadFromUnusedBytes(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+	}{
+		{"padded file path", "04040a0041000000aabb 7fff0400"},
+		{"overlong pci", "010108000001aabb 7fff0400"},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inp := mustDecodeHex(t, tc.inp)
+
+			var p DevicePaths
+			if _, err := p.ReadFrom(bytes.NewReader(inp)); err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if _, ok := p[0].(*UnrecognizedDevicePath); !ok {
+				t.Errorf("ReadFrom() = %T, want *UnrecognizedDevicePath", p[0])
+			}
+
+			var buf bytes.Buffer
+			if _, err := p.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), inp) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), inp)
+			}
+		})
+	}
+}
+
+func TestDevicePaths_FormatAllText(t *testing.T) {
+	// Reference strings as printed by the ConvertDevicePathToText
+	// implementation of EDK2 for each combination of flags.
+	tt := []struct {
+		inp       string
+		full      string
+		display   string
+		shortcuts string
+		both      string
+	}{
+		{
+			`PciRoot(0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)`,
+			`Acpi(PNP0A03,0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)`,
+			`Acpi(PNP0A03,0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)`,
+			`PciRoot(0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)`,
+			`PciRoot(0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)`,
+		},
+		{
+			`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/File(\EFI\BOOT\BOOTX64.EFI)`,
+			`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/\EFI\BOOT\BOOTX64.EFI`,
+			`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/\EFI\BOOT\BOOTX64.EFI`,
+			`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/\EFI\BOOT\BOOTX64.EFI`,
+			`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/\EFI\BOOT\BOOTX64.EFI`,
+		},
+		{
+			`Ata(Secondary,Master,0x2)`,
+			`Ata(Secondary,Master,0x2)`,
+			`Ata(0x2)`,
+			`Ata(Secondary,Master,0x2)`,
+			`Ata(0x2)`,
+		},
+		{
+			`MAC(525400123456,0x1)/IPv4(10.0.0.1,TCP,Static,10.0.0.2,10.0.0.254,255.255.255.0)`,
+			`MAC(525400123456,0x1)/IPv4(10.0.0.1,TCP,Static,10.0.0.2,10.0.0.254,255.255.255.0)`,
+			`MAC(525400123456,0x1)/IPv4(10.0.0.1)`,
+			`MAC(525400123456,0x1)/IPv4(10.0.0.1,TCP,Static,10.0.0.2,10.0.0.254,255.255.255.0)`,
+			`MAC(525400123456,0x1)/IPv4(10.0.0.1)`,
+		},
+		{
+			`UartFlowCtrl(Hardware)/VenVt100()`,
+			`VenMsg(37499A9D-542F-4C89-A026-35DA142094E4,01000000)/VenMsg(DFA66065-B419-11D3-9A2D-0090273FC14D)`,
+			`VenMsg(37499A9D-542F-4C89-A026-35DA142094E4,01000000)/VenMsg(DFA66065-B419-11D3-9A2D-0090273FC14D)`,
+			`UartFlowCtrl(Hardware)/VenVt100()`,
+			`UartFlowCtrl(Hardware)/VenVt100()`,
+		},
+		{
+			`AcpiEx(PNP0A03,0,0x1,,,)`,
+			`AcpiEx(PNP0A03,@@@0000,0x1,,,)`,
+			`PciRoot(0x1)`,
+			`AcpiEx(PNP0A03,@@@0000,0x1,,,)`,
+			`PciRoot(0x1)`,
+		},
+		{
+			`AcpiEx(ABC0A03,0,0x1,,,)`,
+			`AcpiEx(ABC0A03,@@@0000,0x1,,,)`,
+			`AcpiEx(ABC0A03,@@@0000,0x1,,,)`,
+			`AcpiEx(ABC0A03,@@@0000,0x1,,,)`,
+			`AcpiEx(ABC0A03,@@@0000,0x1,,,)`,
+		},
+		{
+			`AcpiExp(PNP0A08,PNP0A03,abc)`,
+			`AcpiExp(PNP0A08,PNP0A03,abc)`,
+			`AcpiExp(PNP0A08,PNP0A03,abc)`,
+			`AcpiExp(PNP0A08,PNP0A03,abc)`,
+			`AcpiExp(PNP0A08,PNP0A03,abc)`,
+		},
+		{
+			`UsbMassStorage(0xffff,0xffff,0x6,0x50)`,
+			`UsbClass(0xffff,0xffff,0x8,0x6,0x50)`,
+			`UsbClass(0xffff,0xffff,0x8,0x6,0x50)`,
+			`UsbMassStorage(0xffff,0xffff,0x6,0x50)`,
+			`UsbMassStorage(0xffff,0xffff,0x6,0x50)`,
+		},
+		{
+			`VirtualCD()`,
+			`VenMedia(3D5ABD30-4175-87CE-6D64-D2ADE523C4BB)`,
+			`VenMedia(3D5ABD30-4175-87CE-6D64-D2ADE523C4BB)`,
+			`VirtualCD()`,
+			`VirtualCD()`,
+		},
+		{
+			`BBS(CDROM,Drive,0x1)`,
+			`BBS(CDROM,Drive,0x1)`,
+			`BBS(CDROM,Drive)`,
+			`BBS(CDROM,Drive,0x1)`,
+			`BBS(CDROM,Drive)`,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.inp, func(t *testing.T) {
+			p, err := ParseText(tc.inp)
+			if err != nil {
+				t.Fatalf("ParseText() error = %v", err)
+			}
+
+			for flags, want := range map[TextFlags]string{
+				0:                            tc.full,
+				DisplayOnly:                  tc.display,
+				AllowShortcuts:               tc.shortcuts,
+				DisplayOnly | AllowShortcuts: tc.both,
+			} {
+				if got := strings.Join(p.FormatAllText(flags), ","); got != want {
+					t.Errorf("FormatAllText(%d) = %v, want %v", flags, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,381 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+var (
+	ErrInvalidText = errors.New("invalid device path text")
+	ErrUnknownNode = errors.New("unknown device path node")
+)
+
+// textParseFn parses the arguments of a Device Path node text
+// representation into a Device Path node.
+type textParseFn func(a *textArgs) DevicePath
+
+// textParsers maps the node names of the text representation to
+// their respective parsing functions.
+//
+// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G14.1012867>
+var textParsers = map[string]textParseFn{
+	"Path":         parseGenericPathText,
+	"HardwarePath": genericTypeTextParser(HardwareType),
+	"AcpiPath":     genericTypeTextParser(ACPIType),
+	"Msg":          genericTypeTextParser(MessagingType),
+	"MediaPath":    genericTypeTextParser(MediaType),
+	"BbsPath":      genericTypeTextParser(BIOSBootType),
+
+	"Pci":          parsePCIText,
+	"PcCard":       parsePCCARDText,
+	"MemoryMapped": parseMemoryMappedText,
+	"VenHw":        vendorTextParser(HardwareType, VendorHardwareSubType),
+	"Ctrl":         parseControllerText,
+	"BMC":          parseBMCText,
+
+	"Acpi":         parseACPIText,
+	"ACPI":         parseACPIText,
+	"AcpiEx":       parseExpandedACPIText,
+	"AcpiExp":      parseExpandedACPIShortText,
+	"AcpiAdr":      parseACPIADRText,
+	"NvdimmHandle": parseNVDIMMText,
+
+	"Ata":          parseATAPIText,
+	"Scsi":         parseSCSIText,
+	"Fibre":        parseFibreChannelText,
+	"FibreEx":      parseFibreChannelExText,
+	"I1394":        parseIEEE1394Text,
+	"USB":          parseUSBText,
+	"UsbWwid":      parseUSBWWIDText,
+	"UsbClass":     parseUSBClassText,
+	"VenMsg":       vendorTextParser(MessagingType, VendorMessagingSubType),
+	"UartFlowCtrl": parseUARTFlowControlText,
+	"SAS":          parseSASText,
+	"SasEx":        parseSASExText,
+	"Unit":         parseDeviceLogicalUnitText,
+	"Sata":         parseSATAText,
+	"NVMe":         parseNVMeNamespaceText,
+	"UFS":          parseUFSText,
+	"SD":           parseSDText,
+	"eMMC":         parseEMMCText,
+
+	"MAC":         parseMACAddressText,
+	"IPv4":        parseIPv4Text,
+	"IPv6":        parseIPv6Text,
+	"Vlan":        parseVLANText,
+	"Infiniband":  parseInfiniBandText,
+	"iSCSI":       parseISCSIText,
+	"Uri":         parseURIText,
+	"Dns":         parseDNSText,
+	"Wi-Fi":       parseWiFiText,
+	"Bluetooth":   parseBluetoothText,
+	"BluetoothLE": parseBluetoothLEText,
+	"RestService": parseRESTServiceText,
+
+	"HD":       parseHardDriveText,
+	"CDROM":    parseCDROMText,
+	"VenMedia": vendorTextParser(MediaType, VendorMediaSubType),
+	"File":     parseFilePathText,
+	"Media":    parseMediaProtocolText,
+	"FvFile":   parseFirmwareFileText,
+	"Fv":       parseFirmwareVolumeText,
+	"Offset":   parseRelativeOffsetRangeText,
+	"RamDisk":  parseRAMDiskText,
+
+	"BBS": parseBIOSBootSpecText,
+}
+
+// textArgs provides typed access to the arguments of a Device Path
+// node text representation.  The first error encountered is recorded
+// and reported after the node has been parsed.
+type textArgs struct {
+	// params is the unsplit parameter list.
+	params string
+	args   []string
+	err    error
+
+	// used is the number of arguments accessed by the parser,
+	// any further arguments are rejected.
+	used int
+}
+
+// all returns the unsplit parameter list, for nodes like File(Path)
+// whose single parameter may contain commas.
+func (a *textArgs) all() string {
+	a.used = len(a.args)
+	return a.params
+}
+
+func (a *textArgs) fail(i int, err error) {
+	if a.err == nil {
+		a.err = fmt.Errorf("argument #%d: %w", i, err)
+	}
+}
+
+// str returns the argument at the given position with surrounding
+// quotes removed. Missing arguments are returned as empty string.
+func (a *textArgs) str(i int) string {
+	if i >= a.used {
+		a.used = i + 1
+	}
+	if i >= len(a.args) {
+		return ""
+	}
+
+	s := strings.TrimSpace(a.args[i])
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return s
+}
+
+// uint parses the argument at the given position as an unsigned
+// integer.  Numbers prefixed with 0x are parsed as hexadecimal
+// numbers, all other numbers as decimal numbers. A missing argument
+// is treated as zero.
+func (a *textArgs) uint(i int, bitSize int) uint64 {
+	v, err := parseTextUint(a.str(i), bitSize)
+	if err != nil {
+		a.fail(i, err)
+	}
+	return v
+}
+
+// hexUint parses the argument at the given position as a hexadecimal
+// unsigned integer with an optional 0x prefix.
+func (a *textArgs) hexUint(i int, bitSize int) uint64 {
+	s := a.str(i)
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	if s == "" {
+		return 0
+	}
+
+	v, err := strconv.ParseUint(s, 16, bitSize)
+	if err != nil {
+		a.fail(i, err)
+	}
+	return v
+}
+
+// be8 parses the argument at the given position as an unsigned
+// 64-bit integer stored in big endian byte order.
+func (a *textArgs) be8(i int) (out [8]byte) {
+	binary.BigEndian.PutUint64(out[:], a.u64(i))
+	return
+}
+
+// enum parses the argument at the given position as one of the
+// given names, returning its index, or as a number otherwise.
+func (a *textArgs) enum(i int, bitSize int, names ...string) uint64 {
+	s := a.str(i)
+	for j, name := range names {
+		if s == name {
+			return uint64(j)
+		}
+	}
+	return a.uint(i, bitSize)
+}
+
+func (a *textArgs) u8(i int) uint8   { return uint8(a.uint(i, 8)) }
+func (a *textArgs) u16(i int) uint16 { return uint16(a.uint(i, 16)) }
+func (a *textArgs) u32(i int) uint32 { return uint32(a.uint(i, 32)) }
+func (a *textArgs) u64(i int) uint64 { return a.uint(i, 64) }
+
+// guid parses the argument at the given position as a GUID.
+func (a *textArgs) guid(i int) (g efiguid.GUID) {
+	g, err := efiguid.FromString(a.str(i))
+	if err != nil {
+		a.fail(i, err)
+	}
+	return
+}
+
+// hex parses the argument at the given position as a sequence of
+// hexadecimal encoded bytes.
+func (a *textArgs) hex(i int) []byte {
+	b, err := hex.DecodeString(a.str(i))
+	if err != nil {
+		a.fail(i, err)
+	}
+	return b
+}
+
+func parseTextUint(s string, bitSize int) (uint64, error) {
+	switch {
+	case s == "":
+		return 0, nil
+	case len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X'):
+		return strconv.ParseUint(s[2:], 16, bitSize)
+	default:
+		return strconv.ParseUint(s, 10, bitSize)
+	}
+}
+
+// splitText splits s at each occurrence of sep outside of
+// parentheses.
+func splitText(s string, sep byte) (out []string) {
+	var depth, start int
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				out = append(out, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, s[start:])
+}
+
+// fillHead sets the Head of d to match its binary representation.
+func fillHead(d DevicePath) error {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return err
+	}
+	return binary.Read(&buf, binary.LittleEndian, d.GetHead())
+}
+
+// ParseNodeText parses the text representation of a single Device
+// Path node.
+//
+// Text without a parameter list is treated as a file path in the same
+// way as File(...).
+//
+// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G14.1012867>
+func ParseNodeText(s string) (DevicePath, error) {
+	s = strings.TrimSpace(s)
+
+	open := strings.IndexByte(s, '(')
+	if open == -1 || !strings.HasSuffix(s, ")") {
+		if strings.ContainsAny(s, "()") {
+			return nil, fmt.Errorf("efi/devicepath: %q: %w", s, ErrInvalidText)
+		}
+		d := parseFilePathText(&textArgs{params: s, args: []string{s}})
+		return d, fillHead(d)
+	}
+
+	name := s[:open]
+	fn, ok := textParsers[name]
+	if !ok {
+		return nil, fmt.Errorf("efi/devicepath: %q: %w", name, ErrUnknownNode)
+	}
+
+	a := &textArgs{params: s[open+1 : len(s)-1]}
+	if a.params != "" {
+		a.args = splitText(a.params, ',')
+	}
+
+	d := fn(a)
+	if a.err == nil && a.used < len(a.args) {
+		a.fail(a.used, fmt.Errorf("unexpected argument: %w", ErrInvalidText))
+	}
+	if a.err != nil {
+		return nil, fmt.Errorf("efi/devicepath: %s: %w", name, a.err)
+	}
+	if d == nil {
+		return nil, fmt.Errorf("efi/devicepath: %s: no node returned: %w", name, ErrInvalidText)
+	}
+	if err := fillHead(d); err != nil {
+		return nil, fmt.Errorf("efi/devicepath: %s: %w", name, err)
+	}
+	return d, nil
+}
+
+// ParseText parses the text representation of a Device Path as
+// produced by the Text method of the individual nodes. Nodes are
+// separated by slashes and Device Path instances by commas.
+//
+// The returned DevicePaths has its instances terminated by End
+// Instance nodes and ends with an End Entire Device Path node.
+//
+// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G14.1012867>
+func ParseText(s string) (DevicePaths, error) {
+	var instances []Instance
+	for _, text := range splitText(s, ',') {
+		var inst Instance
+		for _, node := range splitText(text, '/') {
+			if strings.TrimSpace(node) == "" {
+				continue
+			}
+
+			d, err := ParseNodeText(node)
+			if err != nil {
+				return nil, err
+			}
+			inst = append(inst, d)
+		}
+		instances = append(instances, inst)
+	}
+	return JoinInstances(instances...), nil
+}
+
+// parseGenericPathText parses the generic Path(Type,SubType,Data)
+// representation.
+func parseGenericPathText(a *textArgs) DevicePath {
+	return parseGenericNodeText(a, DevicePathType(a.u8(0)), 1)
+}
+
+// genericTypeTextParser returns a parser for the generic text
+// representation of nodes of type t, i.e. Msg(SubType,Data).
+func genericTypeTextParser(t DevicePathType) textParseFn {
+	return func(a *textArgs) DevicePath {
+		return parseGenericNodeText(a, t, 0)
+	}
+}
+
+// parseGenericNodeText parses the subtype and data arguments of the
+// generic text representations starting at position i.  The
+// resulting node is decoded in the same way as its binary
+// representation would be.
+func parseGenericNodeText(a *textArgs, t DevicePathType, i int) DevicePath {
+	data := a.hex(i + 1)
+	if len(data) > 0xffff-4 {
+		a.fail(i+1, ErrNodeTooLarge)
+		return nil
+	}
+
+	head := Head{
+		Type:    t,
+		SubType: DevicePathSubType(a.u8(i)),
+		Length:  uint16(4 + len(data)),
+	}
+	if a.err != nil {
+		return nil
+	}
+
+	d, err := parseNode(bytes.NewReader(data), head)
+	if err != nil {
+		a.fail(i+1, err)
+	}
+	return d
+}
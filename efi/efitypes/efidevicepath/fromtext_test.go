@@ -0,0 +1,149 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+func TestParseText_RoundTrip(t *testing.T) {
+	tt := []string{
+		`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/File(\EFI\BOOT\BOOTX64.EFI)`,
+		`HD(0,MBR,0x00000000,0x0,0x0)`,
+		`HD(1,MBR,0xa0021243,0x800,0x2ee000)`,
+		`PciRoot(0x0)/Pci(0x1f,0x2)`,
+		`CDROM(0x0,0x10,0x20)`,
+		`VenMedia(3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,aabb)`,
+		`BBS(USB,,0x0)`,
+		`BBS(HD,Floppy,0x0)`,
+		`Path(128,1,0123456789)`,
+		`Pci(0x1,0x0),PciRoot(0x1)`,
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc, func(t *testing.T) {
+			p, err := ParseText(tc)
+			if err != nil {
+				t.Fatalf("ParseText() error = %v", err)
+			}
+			if got := strings.Join(p.AllText(), ","); got != tc {
+				t.Errorf("AllText() = %v, want %v", got, tc)
+			}
+		})
+	}
+}
+
+func TestParseText_Binary(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+		want string
+	}{
+		{
+			"hd and file",
+			`HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/File(EFI\LINUX\GRUB.EFI)`,
+			"04012a00 01000000 0008000000000000 0020030000000000 ffffffffffffffffffffffffffffffff 0202" +
+				"04042a004500460049005c004c0049004e00550058005c0047005200550042002e0045004600490000007fff0400",
+		},
+		{
+			"bare file path",
+			`\a`,
+			"04040a005c0061000000 7fff0400",
+		},
+		{
+			"generic path decodes known nodes",
+			`Path(1,1,021f)`,
+			"01010600021f 7fff0400",
+		},
+		{
+			"acpi with numeric hid",
+			`Acpi(0x0A0341D0,0x1)`,
+			"02010c00d041030a01000000 7fff0400",
+		},
+		{
+			"file path with comma",
+			`File(\a,b)`,
+			"04040e005c0061002c0062000000 7fff0400",
+		},
+		{
+			"instances",
+			`Pci(1,0),Pci(2,0)`,
+			"010106000001 7f010400 010106000002 7fff0400",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParseText(tc.inp)
+			if err != nil {
+				t.Fatalf("ParseText() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if _, err := p.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+
+			want := mustDecodeHex(t, tc.want)
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func TestParseNodeText_Head(t *testing.T) {
+	p, err := ParseNodeText("Pci(1,0)")
+	if err != nil {
+		t.Fatalf("ParseNodeText() error = %v", err)
+	}
+
+	want := Head{Type: HardwareType, SubType: PCISubType, Length: 6}
+	if got := *p.GetHead(); got != want {
+		t.Errorf("GetHead() = %v, want %v", got, want)
+	}
+}
+
+func TestParseNodeText_Errors(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+		want error
+	}{
+		{"unknown node", "Foo(1)", ErrUnknownNode},
+		{"unbalanced", "Pci(1,0", ErrInvalidText},
+		{"bad number", "Pci(x,0)", strconv.ErrSyntax},
+		{"out of range", "Pci(256,0)", strconv.ErrRange},
+		{"non-ucs2 path name", "File(\U0001F600)", efireader.ErrNotUCS2},
+		{"invalid utf-8 wwid", "UsbWwid(0x781,0x5581,0x0,\"\xff\")", efireader.ErrInvalidUTF8},
+		{"extra argument", "Pci(1,2,3,4)", ErrInvalidText},
+		{"extra empty argument", "Fv(8be4df61-93ca-11d2-aa0d-00e098032b8c,)", ErrInvalidText},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseNodeText(tc.inp)
+			if !errors.Is(err, tc.want) {
+				t.Errorf("ParseNodeText() error = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
@@ -75,17 +75,173 @@ func (p *PCIDevicePath) GetHead() *Head {
 }
 
 func (p *PCIDevicePath) Text() string {
-	return fmt.Sprintf("Pci(%d,%d)", p.Function, p.Device)
+	return fmt.Sprintf("Pci(%#x,%#x)", p.Device, p.Function)
 }
 
 func (p *PCIDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
 	return efireader.ReadFields(r, &p.Function, &p.Device)
 }
 
+func (p *PCIDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, PCISubType, p.Function, p.Device)
+}
+
+// parsePCIText parses Pci(Device,Function).
+func parsePCIText(a *textArgs) DevicePath {
+	return &PCIDevicePath{Device: a.u8(0), Function: a.u8(1)}
+}
+
+// PCCARDDevicePath is a PC Card Device Path.
+//
+// Section 10.3.2.2 "PCCARD Device Path"
+type PCCARDDevicePath struct {
+	Head
+
+	// FunctionNumber is the function number of the PC Card.
+	FunctionNumber uint8
+}
+
+func (p *PCCARDDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *PCCARDDevicePath) Text() string {
+	return fmt.Sprintf("PcCard(%#x)", p.FunctionNumber)
+}
+
+func (p *PCCARDDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.FunctionNumber)
+}
+
+func (p *PCCARDDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, PCCARDSubType, p.FunctionNumber)
+}
+
+// parsePCCARDText parses PcCard(Function).
+func parsePCCARDText(a *textArgs) DevicePath {
+	return &PCCARDDevicePath{FunctionNumber: a.u8(0)}
+}
+
+// MemoryMappedDevicePath is a Memory Mapped Device Path.
+//
+// Section 10.3.2.3 "Memory Mapped Device Path"
+type MemoryMappedDevicePath struct {
+	Head
+
+	// MemoryType is the EFI_MEMORY_TYPE of the memory range.
+	MemoryType uint32
+
+	// StartingAddress is the starting memory address.
+	StartingAddress uint64
+
+	// EndingAddress is the ending memory address.
+	EndingAddress uint64
+}
+
+func (p *MemoryMappedDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *MemoryMappedDevicePath) Text() string {
+	return fmt.Sprintf("MemoryMapped(%#x,%#x,%#x)", p.MemoryType, p.StartingAddress, p.EndingAddress)
+}
+
+func (p *MemoryMappedDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.MemoryType, &p.StartingAddress, &p.EndingAddress)
+}
+
+func (p *MemoryMappedDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, MemoryMappedSubType, p.MemoryType, p.StartingAddress, p.EndingAddress)
+}
+
+// parseMemoryMappedText parses MemoryMapped(EfiMemoryType,StartingAddress,EndingAddress).
+func parseMemoryMappedText(a *textArgs) DevicePath {
+	return &MemoryMappedDevicePath{MemoryType: a.u32(0), StartingAddress: a.u64(1), EndingAddress: a.u64(2)}
+}
+
+// ControllerDevicePath is a Controller Device Path.
+//
+// Section 10.3.2.5 "Controller Device Path"
+type ControllerDevicePath struct {
+	Head
+
+	// ControllerNumber is the controller number.
+	ControllerNumber uint32
+}
+
+func (p *ControllerDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *ControllerDevicePath) Text() string {
+	return fmt.Sprintf("Ctrl(%#x)", p.ControllerNumber)
+}
+
+func (p *ControllerDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.ControllerNumber)
+}
+
+func (p *ControllerDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, ControllerSubType, p.ControllerNumber)
+}
+
+// parseControllerText parses Ctrl(Controller).
+func parseControllerText(a *textArgs) DevicePath {
+	return &ControllerDevicePath{ControllerNumber: a.u32(0)}
+}
+
+// BMCDevicePath is a Baseboard Management Controller (BMC) Device
+// Path.
+//
+// Section 10.3.2.6 "BMC Device Path"
+type BMCDevicePath struct {
+	Head
+
+	// InterfaceType is the BMC interface type: 0 for unknown,
+	// 1 for KCS, 2 for SMIC and 3 for BT.
+	InterfaceType uint8
+
+	// BaseAddress is the base address of the BMC.  If the least
+	// significant bit is set the address is in I/O space,
+	// otherwise in memory space.
+	BaseAddress uint64
+}
+
+func (p *BMCDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *BMCDevicePath) Text() string {
+	return fmt.Sprintf("BMC(%#x,%#x)", p.InterfaceType, p.BaseAddress)
+}
+
+func (p *BMCDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.InterfaceType, &p.BaseAddress)
+}
+
+func (p *BMCDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, BMCSubType, p.InterfaceType, p.BaseAddress)
+}
+
+// parseBMCText parses BMC(Type,Address).
+func parseBMCText(a *textArgs) DevicePath {
+	return &BMCDevicePath{InterfaceType: a.u8(0), BaseAddress: a.u64(1)}
+}
+
 func ParseHardwareDevicePath(f io.Reader, h Head) (p DevicePath, err error) {
 	switch h.SubType {
 	case PCISubType:
 		p = &PCIDevicePath{Head: h}
+	case PCCARDSubType:
+		p = &PCCARDDevicePath{Head: h}
+	case MemoryMappedSubType:
+		p = &MemoryMappedDevicePath{Head: h}
+	case VendorHardwareSubType:
+		return parseVendorNode(f, h, &VendorHardwareDevicePath{Head: h})
+	case ControllerSubType:
+		p = &ControllerDevicePath{Head: h}
+	case BMCSubType:
+		p = &BMCDevicePath{Head: h}
 	default:
 		p = &UnrecognizedDevicePath{Head: h}
 	}
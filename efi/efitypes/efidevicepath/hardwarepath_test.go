@@ -0,0 +1,54 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"testing"
+)
+
+func TestHardwareDevicePath(t *testing.T) {
+	runNodeTests(t, []nodeTestCase{
+		{
+			"pci",
+			&PCIDevicePath{Function: 2, Device: 0x1f},
+			"Pci(0x1f,0x2)",
+			"01010600 02 1f",
+		},
+		{
+			"pccard",
+			&PCCARDDevicePath{FunctionNumber: 1},
+			"PcCard(0x1)",
+			"01020500 01",
+		},
+		{
+			"memory mapped",
+			&MemoryMappedDevicePath{MemoryType: 11, StartingAddress: 0xfed00000, EndingAddress: 0xfed003ff},
+			"MemoryMapped(0xb,0xfed00000,0xfed003ff)",
+			"01031800 0b000000 0000d0fe00000000 ff03d0fe00000000",
+		},
+		{
+			"controller",
+			&ControllerDevicePath{ControllerNumber: 2},
+			"Ctrl(0x2)",
+			"01050800 02000000",
+		},
+		{
+			"bmc",
+			&BMCDevicePath{InterfaceType: 1, BaseAddress: 0xca2},
+			"BMC(0x1,0xca2)",
+			"01060d00 01 a20c000000000000",
+		},
+	})
+}
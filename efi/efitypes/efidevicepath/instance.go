@@ -0,0 +1,304 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"strings"
+)
+
+// Instance is a single Device Path instance. It holds the nodes of
+// the instance without the End of Device Path node terminating it.
+//
+// Section 10.3.1 "Generic Device Path Structures"
+type Instance []DevicePath
+
+// Paths splits a packed list of Device Paths, like the FilePathList
+// of a load option, into its Device Paths, each terminated by an End
+// Entire Device Path node unless it is the unterminated last one.
+// The result always holds at least one, possibly empty, Device Path.
+func (p DevicePaths) Paths() (out []DevicePaths) {
+	start := 0
+	for i, d := range p {
+		if st, ok := endSubType(d); ok && st == EndEntireSubType {
+			out = append(out, p[start:i+1])
+			start = i + 1
+		}
+	}
+	if start < len(p) || len(out) == 0 {
+		out = append(out, p[start:])
+	}
+	return
+}
+
+// Instances splits the Device Path into its instances.  For a packed
+// list of Device Paths the instances of all Device Paths are returned
+// in order.  A Device Path always consists of at least one, possibly
+// empty, instance.
+func (p DevicePaths) Instances() (out []Instance) {
+	for _, path := range p.Paths() {
+		out = append(out, path.instances()...)
+	}
+	return
+}
+
+// instances splits a single Device Path into its instances.
+func (p DevicePaths) instances() (out []Instance) {
+	var cur Instance
+	for _, d := range p {
+		if st, ok := endSubType(d); ok {
+			out = append(out, cur)
+			cur = nil
+
+			if st == EndEntireSubType {
+				return
+			}
+			continue
+		}
+		cur = append(cur, d)
+	}
+	return append(out, cur)
+}
+
+// JoinInstances joins the given instances into a single Device Path.
+// The instances are separated by End Instance nodes and the Device
+// Path is terminated by an End Entire Device Path node.
+func JoinInstances(instances ...Instance) (out DevicePaths) {
+	for i, inst := range instances {
+		if i > 0 {
+			out = append(out, &EndOfPath{Head{Type: EndOfPathType, SubType: EndSingleSubType, Length: 4}})
+		}
+		out = append(out, inst...)
+	}
+	return append(out, &EndOfPath{Head{Type: EndOfPathType, SubType: EndEntireSubType, Length: 4}})
+}
+
+// Append returns a new Device Path with the given nodes appended to
+// the last instance of p.  For a packed list of Device Paths the
+// first Device Path is changed and the following ones are kept.
+func (p DevicePaths) Append(nodes ...DevicePath) DevicePaths {
+	return p.withFirstPath(func(instances []Instance) []Instance {
+		last := len(instances) - 1
+		instances[last] = instances[last].Append(nodes...)
+		return instances
+	})
+}
+
+// AppendInstance returns a new Device Path with inst added as an
+// additional instance to p.  For a packed list of Device Paths the
+// instance is added to the first Device Path and the following ones
+// are kept.
+func (p DevicePaths) AppendInstance(inst Instance) DevicePaths {
+	return p.withFirstPath(func(instances []Instance) []Instance {
+		return append(instances, inst)
+	})
+}
+
+// withFirstPath returns a new packed list of Device Paths with the
+// instances of the first Device Path of p replaced by fn.
+func (p DevicePaths) withFirstPath(fn func([]Instance) []Instance) DevicePaths {
+	paths := p.Paths()
+	out := JoinInstances(fn(paths[0].instances())...)
+	for _, path := range paths[1:] {
+		out = append(out, path...)
+	}
+	return out
+}
+
+// Equal reports whether p and o consist of the same Device Paths
+// with the same instances.
+func (p DevicePaths) Equal(o DevicePaths) bool {
+	a, b := p.Paths(), o.Paths()
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !instancesEqual(a[i].instances(), b[i].instances()) {
+			return false
+		}
+	}
+	return true
+}
+
+func instancesEqual(a, b []Instance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Append returns a copy of i with the given nodes appended.
+func (i Instance) Append(nodes ...DevicePath) Instance {
+	out := make(Instance, 0, len(i)+len(nodes))
+	out = append(out, i...)
+	return append(out, nodes...)
+}
+
+// Equal reports whether i and o consist of the same nodes.
+func (i Instance) Equal(o Instance) bool {
+	return len(i) == len(o) && i.HasPrefix(o)
+}
+
+// HasPrefix reports whether the instance begins with the nodes
+// of prefix.
+func (i Instance) HasPrefix(prefix Instance) bool {
+	if len(prefix) > len(i) {
+		return false
+	}
+	for j, d := range prefix {
+		if !NodeEqual(i[j], d) {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSuffix reports whether the instance ends with the nodes
+// of suffix.
+func (i Instance) HasSuffix(suffix Instance) bool {
+	if len(suffix) > len(i) {
+		return false
+	}
+	return i[len(i)-len(suffix):].HasPrefix(suffix)
+}
+
+// IsShortForm reports whether the instance is a short-form Device
+// Path, that is it starts with a Hard Drive or a File Path node and
+// leaves out the nodes leading to the device.
+//
+// Section 3.1.2 "Load Option Processing"
+func (i Instance) IsShortForm() bool {
+	if len(i) == 0 {
+		return false
+	}
+	switch i[0].(type) {
+	case *HardDriveMediaDevicePath, *FilePathDevicePath:
+		return true
+	}
+	return false
+}
+
+// Matches reports whether the instance refers to the same device as
+// the full Device Path instance full.  Short-form instances match
+// any full instance ending with the nodes of the short form, where
+// Hard Drive nodes only have to match in their partition signature.
+// Other instances have to be equal.
+func (i Instance) Matches(full Instance) bool {
+	if !i.IsShortForm() {
+		return i.Equal(full)
+	}
+	if len(i) > len(full) {
+		return false
+	}
+
+	tail := full[len(full)-len(i):]
+	if hd, ok := i[0].(*HardDriveMediaDevicePath); ok {
+		return partitionMatch(tail[0], hd) && tail[1:].Equal(i[1:])
+	}
+	return tail.Equal(i)
+}
+
+// partitionMatch reports whether d is a Hard Drive node referring to
+// the same partition as hd.  Like the MatchPartitionDevicePathNode
+// function of EDK2 only the partition signatures are compared, Hard
+// Drive nodes without a signature never match.
+func partitionMatch(d DevicePath, hd *HardDriveMediaDevicePath) bool {
+	o, ok := d.(*HardDriveMediaDevicePath)
+	if !ok || o.SignatureType != hd.SignatureType {
+		return false
+	}
+
+	switch hd.SignatureType {
+	case PCATSignatureType:
+		return bytes.Equal(o.PartitionSignature[:4], hd.PartitionSignature[:4])
+	case GUIDSignatureType:
+		return o.PartitionSignature == hd.PartitionSignature
+	}
+	return false
+}
+
+// Expand expands a short-form instance against a list of known full
+// Device Path instances, like the ones of the devices present in the
+// system, the same way firmware does when processing a load option.
+//
+// A short form starting with a Hard Drive node is expanded using
+// every known instance containing a Hard Drive node with the same
+// partition signature, by replacing the short form's first node with
+// the known instance up to and including the matching node.  A short
+// form starting with a File Path node is appended to every known
+// instance.
+//
+// Instances which are not in short form are returned unchanged.
+func (i Instance) Expand(known []Instance) (out []Instance) {
+	if !i.IsShortForm() {
+		return []Instance{i}
+	}
+
+	hd, _ := i[0].(*HardDriveMediaDevicePath)
+	for _, k := range known {
+		if hd == nil {
+			out = append(out, k.Append(i...))
+			continue
+		}
+
+		for j, d := range k {
+			if partitionMatch(d, hd) {
+				out = append(out, k[:j+1].Append(i[1:]...))
+				break
+			}
+		}
+	}
+	return
+}
+
+// Text returns the text representation of the instance.
+func (i Instance) Text() string {
+	return i.text(DevicePath.Text)
+}
+
+// FormatText returns the text representation of the instance
+// selected by flags.
+func (i Instance) FormatText(flags TextFlags) string {
+	return i.text(func(d DevicePath) string { return NodeText(d, flags) })
+}
+
+func (i Instance) text(fn func(DevicePath) string) string {
+	var b strings.Builder
+	for j, d := range i {
+		if j > 0 {
+			b.WriteString("/")
+		}
+		b.WriteString(fn(d))
+	}
+	return b.String()
+}
+
+// NodeEqual reports whether the nodes a and b have the same binary
+// representation.  Nodes which can not be encoded are never equal.
+func NodeEqual(a, b DevicePath) bool {
+	var ab, bb bytes.Buffer
+	if _, err := a.WriteTo(&ab); err != nil {
+		return false
+	}
+	if _, err := b.WriteTo(&bb); err != nil {
+		return false
+	}
+	return bytes.Equal(ab.Bytes(), bb.Bytes())
+}
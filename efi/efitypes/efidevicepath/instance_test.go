@@ -0,0 +1,274 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const (
+	testDiskPath = `PciRoot(0x0)/Pci(0x1d,0x0)/NVMe(0x1,00-25-38-5b-71-b0-a1-c2)`
+	testHDNode   = `HD(1,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)`
+	testFileNode = `File(\EFI\BOOT\BOOTX64.EFI)`
+)
+
+func mustParseInstance(t *testing.T, s string) Instance {
+	t.Helper()
+	p, err := ParseText(s)
+	if err != nil {
+		t.Fatalf("ParseText(%q) error = %v", s, err)
+	}
+	instances := p.Instances()
+	if len(instances) != 1 {
+		t.Fatalf("ParseText(%q) has %d instances, want 1", s, len(instances))
+	}
+	return instances[0]
+}
+
+func TestDevicePaths_Instances(t *testing.T) {
+	var (
+		pci1 = &PCIDevicePath{Device: 1}
+		pci2 = &PCIDevicePath{Device: 2}
+
+		endSingle = &EndOfPath{Head{Type: EndOfPathType, SubType: EndSingleSubType}}
+		endEntire = &EndOfPath{Head{Type: EndOfPathType, SubType: EndEntireSubType}}
+	)
+
+	tt := []struct {
+		name string
+		inp  DevicePaths
+		want []string
+	}{
+		{"empty", nil, []string{""}},
+		{"end only", DevicePaths{endEntire}, []string{""}},
+		{"unterminated", DevicePaths{pci1}, []string{"Pci(0x1,0x0)"}},
+		{"single", DevicePaths{pci1, endEntire}, []string{"Pci(0x1,0x0)"}},
+		{"multiple", DevicePaths{pci1, endSingle, pci2, endEntire}, []string{"Pci(0x1,0x0)", "Pci(0x2,0x0)"}},
+		{"packed", DevicePaths{pci1, endEntire, pci2, endEntire}, []string{"Pci(0x1,0x0)", "Pci(0x2,0x0)"}},
+		{"packed unterminated", DevicePaths{pci1, endEntire, pci2}, []string{"Pci(0x1,0x0)", "Pci(0x2,0x0)"}},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.inp.Instances()
+			if len(got) != len(tc.want) {
+				t.Fatalf("Instances() = %d instances, want %d", len(got), len(tc.want))
+			}
+			for i, inst := range got {
+				if inst.Text() != tc.want[i] {
+					t.Errorf("Instances()[%d] = %v, want %v", i, inst.Text(), tc.want[i])
+				}
+			}
+
+			if len(tc.inp.Paths()) > 1 {
+				return
+			}
+			joined := JoinInstances(got...)
+			if !joined.Equal(tc.inp) {
+				t.Errorf("JoinInstances() = %v, want %v", joined.AllText(), tc.want)
+			}
+		})
+	}
+}
+
+func TestDevicePaths_Paths(t *testing.T) {
+	p, err := ParseText("Pci(0x1,0x0),Pci(0x2,0x0)")
+	if err != nil {
+		t.Fatalf("ParseText() error = %v", err)
+	}
+	q, err := ParseText("Pci(0x3,0x0)")
+	if err != nil {
+		t.Fatalf("ParseText() error = %v", err)
+	}
+	packed := append(append(DevicePaths{}, p...), q...)
+
+	paths := packed.Paths()
+	if len(paths) != 2 {
+		t.Fatalf("Paths() = %d Device Paths, want 2", len(paths))
+	}
+	if !paths[0].Equal(p) || !paths[1].Equal(q) {
+		t.Errorf("Paths() = %v, %v, want %v, %v", paths[0].AllText(), paths[1].AllText(), p.AllText(), q.AllText())
+	}
+	if packed.Equal(p) {
+		t.Errorf("Equal() = true, ignoring the packed Device Path")
+	}
+
+	got := packed.Append(&ControllerDevicePath{ControllerNumber: 1})
+	if want := "Pci(0x1,0x0),Pci(0x2,0x0)/Ctrl(0x1),Pci(0x3,0x0)"; strings.Join(got.AllText(), ",") != want {
+		t.Errorf("Append() = %v, want %v", got.AllText(), want)
+	}
+
+	got = packed.AppendInstance(mustParseInstance(t, "Pci(0x4,0x0)"))
+	var buf bytes.Buffer
+	if _, err := got.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := mustDecodeHex(t, "010106000001 7f010400 010106000002 7f010400 010106000004 7fff0400 010106000003 7fff0400")
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestDevicePaths_Append(t *testing.T) {
+	p, err := ParseText("Pci(0x1,0x0),Pci(0x2,0x0)")
+	if err != nil {
+		t.Fatalf("ParseText() error = %v", err)
+	}
+
+	got := p.Append(&ControllerDevicePath{ControllerNumber: 1})
+	if want := "Pci(0x1,0x0),Pci(0x2,0x0)/Ctrl(0x1)"; strings.Join(got.AllText(), ",") != want {
+		t.Errorf("Append() = %v, want %v", got.AllText(), want)
+	}
+
+	got = p.AppendInstance(mustParseInstance(t, "Pci(0x3,0x0)"))
+	if want := "Pci(0x1,0x0),Pci(0x2,0x0),Pci(0x3,0x0)"; strings.Join(got.AllText(), ",") != want {
+		t.Errorf("AppendInstance() = %v, want %v", got.AllText(), want)
+	}
+
+	if want := "Pci(0x1,0x0),Pci(0x2,0x0)"; strings.Join(p.AllText(), ",") != want {
+		t.Errorf("Append() modified the original Device Path to %v", p.AllText())
+	}
+
+	var buf bytes.Buffer
+	if _, err := got.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want := mustDecodeHex(t, "010106000001 7f010400 010106000002 7f010400 010106000003 7fff0400")
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+	}
+
+	// A zero EndOfPath node is written as End Entire node and has
+	// to terminate the Device Path in the same way.
+	got = DevicePaths{&PCIDevicePath{Device: 1}, &EndOfPath{}}.Append(&ControllerDevicePath{ControllerNumber: 1})
+	buf.Reset()
+	if _, err := got.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	want = mustDecodeHex(t, "010106000001 01050800 01000000 7fff0400")
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), want)
+	}
+}
+
+func TestNodeEqual(t *testing.T) {
+	parsed, err := ParseNodeText("Pci(0x1,0x2)")
+	if err != nil {
+		t.Fatalf("ParseNodeText() error = %v", err)
+	}
+
+	if !NodeEqual(parsed, &PCIDevicePath{Device: 1, Function: 2}) {
+		t.Errorf("NodeEqual() = false for nodes with the same encoding")
+	}
+	if NodeEqual(parsed, &PCIDevicePath{Device: 2, Function: 1}) {
+		t.Errorf("NodeEqual() = true for nodes with different encodings")
+	}
+}
+
+func TestInstance_Matches(t *testing.T) {
+	full := mustParseInstance(t, testDiskPath+"/"+testHDNode+"/"+testFileNode)
+
+	tt := []struct {
+		name  string
+		inp   string
+		short bool
+		want  bool
+	}{
+		{"full", testDiskPath + "/" + testHDNode + "/" + testFileNode, false, true},
+		{"hard drive", testHDNode + "/" + testFileNode, true, true},
+		{"file path", testFileNode, true, true},
+		{"other partition", `HD(2,GPT,EEEEEEEE-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/` + testFileNode, true, false},
+		{"same signature", `HD(2,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x1000,0x100)/` + testFileNode, true, true},
+		{"mbr signature", `HD(1,MBR,0xffffffff,0x800,0x32000)/` + testFileNode, true, false},
+		{"other file", testHDNode + `/File(\EFI\BOOT\GRUBX64.EFI)`, true, false},
+		{"prefix only", testDiskPath, false, false},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inst := mustParseInstance(t, tc.inp)
+			if got := inst.IsShortForm(); got != tc.short {
+				t.Errorf("IsShortForm() = %v, want %v", got, tc.short)
+			}
+			if got := inst.Matches(full); got != tc.want {
+				t.Errorf("Matches() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	if !full.HasPrefix(mustParseInstance(t, testDiskPath)) {
+		t.Errorf("HasPrefix() = false, want true")
+	}
+}
+
+func TestInstance_Expand(t *testing.T) {
+	known := []Instance{
+		mustParseInstance(t, testDiskPath),
+		mustParseInstance(t, testDiskPath+"/"+testHDNode),
+		mustParseInstance(t, `PciRoot(0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)`),
+	}
+
+	tt := []struct {
+		name string
+		inp  string
+		want []string
+	}{
+		{
+			"hard drive",
+			testHDNode + "/" + testFileNode,
+			[]string{testDiskPath + "/" + testHDNode + "/" + testFileNode},
+		},
+		{
+			"file path",
+			testFileNode,
+			[]string{
+				testDiskPath + "/" + testFileNode,
+				testDiskPath + "/" + testHDNode + "/" + testFileNode,
+				`PciRoot(0x0)/Pci(0x1f,0x2)/Sata(0x0,0xffff,0x0)/` + testFileNode,
+			},
+		},
+		{
+			"same signature",
+			`HD(2,GPT,FFFFFFFF-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x1000,0x100)/` + testFileNode,
+			[]string{testDiskPath + "/" + testHDNode + "/" + testFileNode},
+		},
+		{
+			"unknown hard drive",
+			`HD(1,GPT,EEEEEEEE-FFFF-FFFF-FFFF-FFFFFFFFFFFF,0x800,0x32000)/` + testFileNode,
+			nil,
+		},
+		{
+			"full path",
+			`PciRoot(0x1)/` + testFileNode,
+			[]string{`PciRoot(0x1)/` + testFileNode},
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got := mustParseInstance(t, tc.inp).Expand(known)
+			if len(got) != len(tc.want) {
+				t.Fatalf("Expand() = %d instances, want %d", len(got), len(tc.want))
+			}
+			for i, inst := range got {
+				if inst.Text() != tc.want[i] {
+					t.Errorf("Expand()[%d] = %v, want %v", i, inst.Text(), tc.want[i])
+				}
+			}
+		})
+	}
+}
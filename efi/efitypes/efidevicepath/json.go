@@ -0,0 +1,689 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+var (
+	ErrUnknownField = errors.New("unknown field")
+)
+
+// typeNames maps Device Path types to their names in the JSON
+// representation of nodes.
+var typeNames = map[DevicePathType]string{
+	HardwareType:  "Hardware",
+	ACPIType:      "ACPI",
+	MessagingType: "Messaging",
+	MediaType:     "Media",
+	BIOSBootType:  "BIOSBoot",
+	EndOfPathType: "End",
+}
+
+// MarshalText implements encoding.TextMarshaler and returns the name
+// of the Device Path type or its decimal value for unnamed types.
+func (t DevicePathType) MarshalText() ([]byte, error) {
+	if name, ok := typeNames[t]; ok {
+		return []byte(name), nil
+	}
+	return []byte(strconv.Itoa(int(t))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *DevicePathType) UnmarshalText(b []byte) error {
+	for k, name := range typeNames {
+		if name == string(b) {
+			*t = k
+			return nil
+		}
+	}
+
+	v, err := strconv.ParseUint(string(b), 10, 8)
+	if err != nil {
+		return fmt.Errorf("efi/devicepath: type %q: %w", b, err)
+	}
+	*t = DevicePathType(v)
+	return nil
+}
+
+// subTypeNames maps Device Path subtypes to their names in the JSON
+// representation of nodes.  The names follow the specification.
+var subTypeNames = map[DevicePathType]map[DevicePathSubType]string{
+	HardwareType: {
+		PCISubType:            "PCI",
+		PCCARDSubType:         "PCCARD",
+		MemoryMappedSubType:   "MemoryMapped",
+		VendorHardwareSubType: "Vendor",
+		ControllerSubType:     "Controller",
+		BMCSubType:            "BMC",
+	},
+	ACPIType: {
+		ACPISubType:         "ACPI",
+		ExpandedACPISubType: "ExpandedACPI",
+		ACPIADRSubType:      "ADR",
+		NVDIMMSubType:       "NVDIMM",
+	},
+	MessagingType: {
+		ATAPISubType:             "ATAPI",
+		SCSISubType:              "SCSI",
+		FibreChannelSubType:      "FibreChannel",
+		IEEE1394SubType:          "IEEE1394",
+		USBSubType:               "USB",
+		I2OSubType:               "I2O",
+		InfiniBandSubType:        "InfiniBand",
+		VendorMessagingSubType:   "Vendor",
+		MACAddressSubType:        "MACAddress",
+		IPv4SubType:              "IPv4",
+		IPv6SubType:              "IPv6",
+		UARTSubType:              "UART",
+		USBClassSubType:          "USBClass",
+		USBWWIDSubType:           "USBWWID",
+		DeviceLogicalUnitSubType: "DeviceLogicalUnit",
+		SATASubType:              "SATA",
+		ISCSISubType:             "iSCSI",
+		VLANSubType:              "VLAN",
+		FibreChannelExSubType:    "FibreChannelEx",
+		SASExSubType:             "SASEx",
+		NVMeNamespaceSubType:     "NVMeNamespace",
+		URISubType:               "URI",
+		UFSSubType:               "UFS",
+		SDSubType:                "SD",
+		BluetoothSubType:         "Bluetooth",
+		WiFiSubType:              "WiFi",
+		EMMCSubType:              "eMMC",
+		BluetoothLESubType:       "BluetoothLE",
+		DNSSubType:               "DNS",
+		NVDIMMNamespaceSubType:   "NVDIMMNamespace",
+		RESTServiceSubType:       "RESTService",
+	},
+	MediaType: {
+		HardDriveSubType:           "HardDrive",
+		CDROMSubType:               "CDROM",
+		VendorMediaSubType:         "Vendor",
+		FilePathSubType:            "FilePath",
+		MediaProtocolSubType:       "MediaProtocol",
+		PIWGFirmwareFileSubType:    "PIWGFirmwareFile",
+		PIWGFirmwareVolumeSubType:  "PIWGFirmwareVolume",
+		RelativeOffsetRangeSubType: "RelativeOffsetRange",
+		RAMDiskSubType:             "RAMDisk",
+	},
+	BIOSBootType: {
+		BIOSBootSpecSubType: "BIOSBootSpec",
+	},
+	EndOfPathType: {
+		EndSingleSubType: "EndSingle",
+		EndEntireSubType: "EndEntire",
+	},
+}
+
+// subTypeName returns the name of the subtype st of the type t or
+// its decimal value for unnamed subtypes.
+func subTypeName(t DevicePathType, st DevicePathSubType) string {
+	if name, ok := subTypeNames[t][st]; ok {
+		return name
+	}
+	return strconv.Itoa(int(st))
+}
+
+// parseSubTypeName returns the subtype of the type t with the given
+// name or decimal value.
+func parseSubTypeName(t DevicePathType, s string) (DevicePathSubType, error) {
+	for k, name := range subTypeNames[t] {
+		if name == s {
+			return k, nil
+		}
+	}
+
+	v, err := strconv.ParseUint(s, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("efi/devicepath: subtype %q: %w", s, err)
+	}
+	return DevicePathSubType(v), nil
+}
+
+// isVendorSubType reports whether nodes of the type t and subtype st
+// are vendor-defined nodes starting with a vendor GUID.
+func isVendorSubType(t DevicePathType, st DevicePathSubType) bool {
+	switch t {
+	case HardwareType:
+		return st == VendorHardwareSubType
+	case MessagingType:
+		return st == VendorMessagingSubType
+	case MediaType:
+		return st == VendorMediaSubType
+	}
+	return false
+}
+
+// jsonKey identifies the node type with a structured JSON
+// representation.  Vendor-defined nodes are distinguished by their
+// vendor GUID, the zero GUID identifies the generic vendor nodes.
+type jsonKey struct {
+	t  DevicePathType
+	st DevicePathSubType
+	g  efiguid.GUID
+}
+
+// jsonNodes holds the node types with a structured JSON
+// representation.
+var jsonNodes = map[jsonKey]reflect.Type{}
+
+// lookup returns the node type with a structured JSON representation
+// for k, falling back to the generic vendor node.
+func (k jsonKey) lookup() (reflect.Type, bool) {
+	if t, ok := jsonNodes[k]; ok {
+		return t, true
+	}
+	k.g = efiguid.GUID{}
+	t, ok := jsonNodes[k]
+	return t, ok
+}
+
+func init() {
+	for _, d := range []DevicePath{
+		// Hardware Device Path nodes.
+		&PCIDevicePath{},
+		&PCCARDDevicePath{},
+		&MemoryMappedDevicePath{},
+		&VendorHardwareDevicePath{},
+		&ControllerDevicePath{},
+		&BMCDevicePath{},
+
+		// ACPI Device Path nodes.
+		&ACPIPath{},
+		&ExpandedACPIPath{},
+		&ACPIADRPath{},
+		&NVDIMMPath{},
+
+		// Messaging Device Path nodes.
+		&ATAPIDevicePath{},
+		&SCSIDevicePath{},
+		&FibreChannelDevicePath{},
+		&FibreChannelExDevicePath{},
+		&IEEE1394DevicePath{},
+		&USBDevicePath{},
+		&USBWWIDDevicePath{},
+		&USBClassDevicePath{},
+		&DeviceLogicalUnitDevicePath{},
+		&SATADevicePath{},
+		&SASDevicePath{},
+		&SASExDevicePath{},
+		&NVMeNamespaceDevicePath{},
+		&UFSDevicePath{},
+		&SDDevicePath{},
+		&EMMCDevicePath{},
+		&MACAddressDevicePath{},
+		&IPv4DevicePath{},
+		&IPv6DevicePath{},
+		&VLANDevicePath{},
+		&InfiniBandDevicePath{},
+		&ISCSIDevicePath{},
+		&URIDevicePath{},
+		&DNSDevicePath{},
+		&WiFiDevicePath{},
+		&BluetoothDevicePath{},
+		&BluetoothLEDevicePath{},
+		&RESTServiceDevicePath{},
+		&VendorMessagingDevicePath{},
+		&TerminalDevicePath{TerminalType: PCANSITerminalGUID},
+		&TerminalDevicePath{TerminalType: VT100TerminalGUID},
+		&TerminalDevicePath{TerminalType: VT100PlusTerminalGUID},
+		&TerminalDevicePath{TerminalType: VTUTF8TerminalGUID},
+		&UARTFlowControlDevicePath{},
+
+		// Media Device Path nodes.
+		&HardDriveMediaDevicePath{},
+		&CDROMDevicePath{},
+		&VendorMediaDevicePath{},
+		&FilePathDevicePath{},
+		&MediaProtocolDevicePath{},
+		&FirmwareFileDevicePath{},
+		&FirmwareVolumeDevicePath{},
+		&RelativeOffsetRangeDevicePath{},
+		&RAMDiskDevicePath{},
+		&LinuxInitrdMediaDevicePath{},
+		&VirtualMediaDevicePath{DiskType: VirtualDiskGUID},
+		&VirtualMediaDevicePath{DiskType: VirtualCDGUID},
+
+		// BIOS Boot Specification Device Path nodes.
+		&BIOSBootSpecPath{},
+
+		// End of Device Path nodes.
+		&EndOfPath{Head: Head{SubType: EndSingleSubType}},
+		&EndOfPath{Head: Head{SubType: EndEntireSubType}},
+	} {
+		var b bytes.Buffer
+		if _, err := d.WriteTo(&b); err != nil {
+			panic(fmt.Sprintf("efi/devicepath: %T: %v", d, err))
+		}
+		jsonNodes[nodeJSONKey(b.Bytes())] = reflect.TypeOf(d).Elem()
+	}
+}
+
+// nodeJSONKey returns the jsonKey of the encoded node b.
+func nodeJSONKey(b []byte) jsonKey {
+	k := jsonKey{t: DevicePathType(b[0]), st: DevicePathSubType(b[1])}
+	if isVendorSubType(k.t, k.st) && len(b) >= 4+len(k.g) {
+		copy(k.g[:], b[4:])
+	}
+	return k
+}
+
+// jsonNode is the JSON representation of a Device Path node.
+type jsonNode struct {
+	Type    DevicePathType  `json:"type"`
+	SubType string          `json:"subType"`
+	Vendor  *efiguid.GUID   `json:"vendor,omitempty"`
+	Length  uint16          `json:"length,omitempty"`
+	Text    string          `json:"text,omitempty"`
+	Fields  json.RawMessage `json:"fields,omitempty"`
+	Data    *string         `json:"data,omitempty"`
+}
+
+// MarshalNodeJSON returns the JSON representation of the node d, an
+// object of the following form:
+//
+//	{
+//	  "type": "Media",
+//	  "subType": "FilePath",
+//	  "length": 42,
+//	  "text": "File(\\EFI\\BOOT\\BOOTX64.EFI)",
+//	  "fields": {"PathName": "\\EFI\\BOOT\\BOOTX64.EFI"}
+//	}
+//
+// "type" holds the name of the node type, one of Hardware, ACPI,
+// Messaging, Media, BIOSBoot and End, or its decimal value as string
+// for other types.  "subType" likewise holds the name of the subtype
+// as given by the specification, e.g. PCI, ExpandedACPI, USBClass,
+// HardDrive, Vendor or EndEntire, or its decimal value as string.
+//
+// "fields" holds the exported fields of the node keyed by their Go
+// names.  Integers and booleans are encoded as JSON numbers and
+// booleans, GUIDs as strings of the form returned by
+// efiguid.GUID.String, byte arrays and byte slices as lowercase
+// hexadecimal strings, and fields holding UTF-16 or ASCII strings as
+// JSON strings. Slices of other types are encoded as JSON arrays.
+// Vendor-defined nodes additionally carry their vendor GUID in
+// "vendor", as it selects the fields of nodes like the UART Flow
+// Control Messaging Path.
+//
+// "length" holds the length of the node as read from its binary
+// representation and is omitted for nodes built in memory.  It is
+// needed to preserve the legacy encodings of some nodes.
+//
+// "text" holds the text representation of the node and is ignored
+// when decoding.
+//
+// Nodes without a structured representation, like nodes unknown to
+// this package or vendor-defined nodes decoded by a function given
+// to RegisterVendorNode, carry their node body as hexadecimal string
+// in "data" instead of "fields".  So do nodes whose fields can not
+// be represented without loss, e.g. file paths holding unpaired
+// surrogates or URIs holding bytes outside of ASCII:
+//
+//	{"type": "128", "subType": "1", "length": 9, "text": "Path(128,1,0123456789)", "data": "0123456789"}
+//
+// Decoding the JSON representation of a node yields a node with the
+// same binary representation as the original one.
+func MarshalNodeJSON(d DevicePath) ([]byte, error) {
+	var body bytes.Buffer
+	if _, err := d.WriteTo(&body); err != nil {
+		return nil, err
+	}
+
+	// The type and subtype are taken from the encoded node since
+	// nodes built in memory don't necessarily have their Head set.
+	b := body.Bytes()
+	k := nodeJSONKey(b)
+	out := jsonNode{
+		Type:    k.t,
+		SubType: subTypeName(k.t, k.st),
+		Length:  d.GetHead().Length,
+		Text:    d.Text(),
+	}
+
+	// Nodes whose fields can not be represented without loss, like
+	// file paths holding unpaired surrogates, fall back to "data".
+	v := reflect.ValueOf(d)
+	if t, ok := k.lookup(); ok && v.Kind() == reflect.Pointer && v.Elem().Type() == t {
+		if fields, err := marshalFields(v.Elem()); err == nil {
+			if isVendorSubType(k.t, k.st) {
+				out.Vendor = &k.g
+			}
+			out.Fields = fields
+		}
+	}
+	if out.Fields == nil {
+		data := hex.EncodeToString(b[4:])
+		out.Data = &data
+	}
+
+	return json.Marshal(&out)
+}
+
+// UnmarshalNodeJSON returns the node described by the given JSON
+// representation.
+func UnmarshalNodeJSON(b []byte) (DevicePath, error) {
+	var inp jsonNode
+	if err := json.Unmarshal(b, &inp); err != nil {
+		return nil, err
+	}
+	st, err := parseSubTypeName(inp.Type, inp.SubType)
+	if err != nil {
+		return nil, err
+	}
+	head := Head{Type: inp.Type, SubType: st, Length: inp.Length}
+
+	if inp.Data != nil {
+		data, err := hex.DecodeString(*inp.Data)
+		if err != nil {
+			return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", head.Type, head.SubType, err)
+		}
+		if len(data) > 0xffff-4 {
+			return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", head.Type, head.SubType, ErrNodeTooLarge)
+		}
+		head.Length = uint16(4 + len(data))
+		return parseNode(bytes.NewReader(data), head)
+	}
+	if inp.Fields == nil {
+		return nil, fmt.Errorf("efi/devicepath: type %d-%d: missing node data", head.Type, head.SubType)
+	}
+
+	k := jsonKey{t: head.Type, st: head.SubType}
+	if inp.Vendor != nil {
+		k.g = *inp.Vendor
+	}
+	t, ok := k.lookup()
+	if !ok {
+		return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", head.Type, head.SubType, ErrUnknownNode)
+	}
+
+	v := reflect.New(t)
+	if err := unmarshalFields(v.Elem(), inp.Fields); err != nil {
+		return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", head.Type, head.SubType, err)
+	}
+
+	d := v.Interface().(DevicePath)
+	*d.GetHead() = head
+	return d, nil
+}
+
+// jsonTagKey is the struct tag key marking byte slice fields which
+// hold strings, its value is one of utf16z, utf16, asciiz and ascii
+// for null terminated and unterminated UTF-16 and ASCII strings.
+const jsonTagKey = "efijson"
+
+// marshalFields returns a JSON object holding the exported fields of
+// the node struct v in their declaration order.
+func marshalFields(v reflect.Value) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+
+		value, err := marshalField(v.Field(i), f.Tag.Get(jsonTagKey))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// unmarshalFields sets the exported fields of the node struct v
+// from the JSON object b.
+func unmarshalFields(v reflect.Value, b []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return err
+	}
+
+	t := v.Type()
+	for name, raw := range fields {
+		f, ok := t.FieldByName(name)
+		if !ok || f.Anonymous || !f.IsExported() || len(f.Index) != 1 {
+			return fmt.Errorf("field %s: %w", name, ErrUnknownField)
+		}
+
+		if err := unmarshalField(v.Field(f.Index[0]), raw, f.Tag.Get(jsonTagKey)); err != nil {
+			return fmt.Errorf("field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isByteSequence reports whether t is a byte array or byte slice.
+func isByteSequence(t reflect.Type) bool {
+	return (t.Kind() == reflect.Array || t.Kind() == reflect.Slice) && t.Elem().Kind() == reflect.Uint8
+}
+
+// decodeStringField strictly decodes the string field b with the
+// given tag, failing on any input unmarshalField would not encode to
+// the same bytes.  Empty terminated strings are accepted as nodes
+// write them as the empty string.
+func decodeStringField(b []byte, tag string) (string, error) {
+	switch tag {
+	case "utf16z":
+		if len(b) == 0 {
+			return "", nil
+		}
+		return efireader.DecodeUTF16Z(b, efireader.UTF16)
+	case "utf16":
+		return efireader.DecodeUTF16(b, efireader.UTF16)
+	case "asciiz":
+		if len(b) == 0 {
+			return "", nil
+		}
+		return efireader.DecodeASCIIZ(b)
+	case "ascii":
+		return efireader.DecodeASCII(b)
+	}
+	return "", fmt.Errorf("unknown tag %q", tag)
+}
+
+func marshalField(v reflect.Value, tag string) ([]byte, error) {
+	if tag != "" {
+		s, err := decodeStringField(v.Bytes(), tag)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(s)
+	}
+
+	switch {
+	case v.Type().Implements(textMarshalerType):
+		return json.Marshal(v.Interface())
+
+	case isByteSequence(v.Type()):
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return json.Marshal(hex.EncodeToString(b))
+
+	case v.Kind() == reflect.Slice:
+		var b bytes.Buffer
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			value, err := marshalField(v.Index(i), "")
+			if err != nil {
+				return nil, err
+			}
+			b.Write(value)
+		}
+		b.WriteByte(']')
+		return b.Bytes(), nil
+	}
+
+	return json.Marshal(v.Interface())
+}
+
+func unmarshalField(v reflect.Value, b []byte, tag string) error {
+	if tag != "" {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+
+		switch tag {
+		case "utf16z":
+			u, err := efireader.EncodeUTF16Z(s, efireader.UTF16)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(u)
+		case "utf16":
+			u, err := efireader.EncodeUTF16(s, efireader.UTF16)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(u)
+		case "asciiz":
+			v.SetBytes(append([]byte(s), 0))
+		default:
+			v.SetBytes([]byte(s))
+		}
+		return nil
+	}
+
+	switch {
+	case v.Addr().Type().Implements(textUnmarshalerType):
+		return json.Unmarshal(b, v.Addr().Interface())
+
+	case isByteSequence(v.Type()):
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		data, err := hex.DecodeString(s)
+		if err != nil {
+			return err
+		}
+
+		if v.Kind() == reflect.Slice {
+			v.SetBytes(data)
+		} else if len(data) != v.Len() {
+			return fmt.Errorf("got %d bytes, want %d", len(data), v.Len())
+		} else {
+			reflect.Copy(v, reflect.ValueOf(data))
+		}
+		return nil
+
+	case v.Kind() == reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(b, &items); err != nil {
+			return err
+		}
+
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := unmarshalField(s.Index(i), item, ""); err != nil {
+				return fmt.Errorf("item #%d: %w", i, err)
+			}
+		}
+		v.Set(s)
+		return nil
+	}
+
+	return json.Unmarshal(b, v.Addr().Interface())
+}
+
+// MarshalJSON implements json.Marshaler and returns the nodes of the
+// instance as JSON array.
+func (i Instance) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for j, d := range i {
+		if j > 0 {
+			b.WriteByte(',')
+		}
+		node, err := MarshalNodeJSON(d)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(node)
+	}
+	b.WriteByte(']')
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (i *Instance) UnmarshalJSON(b []byte) error {
+	var nodes []json.RawMessage
+	if err := json.Unmarshal(b, &nodes); err != nil {
+		return err
+	}
+
+	out := make(Instance, len(nodes))
+	for j, node := range nodes {
+		d, err := UnmarshalNodeJSON(node)
+		if err != nil {
+			return fmt.Errorf("node #%d: %w", j, err)
+		}
+		out[j] = d
+	}
+	*i = out
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler and returns the nodes of the
+// Device Path as JSON array of nodes as returned by MarshalNodeJSON.
+// The End of Device Path nodes separating and terminating instances
+// and packed Device Paths are kept, so that decoding the JSON
+// representation yields the same nodes.
+func (p DevicePaths) MarshalJSON() ([]byte, error) {
+	return Instance(p).MarshalJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (p *DevicePaths) UnmarshalJSON(b []byte) error {
+	var nodes Instance
+	if err := json.Unmarshal(b, &nodes); err != nil {
+		return err
+	}
+	*p = DevicePaths(nodes)
+	return nil
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+func TestMarshalNodeJSON(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  DevicePath
+		want string
+	}{
+		{
+			"hard drive",
+			&HardDriveMediaDevicePath{
+				PartitionNumber:    1,
+				PartitionStartLBA:  0x800,
+				PartitionSizeLBA:   0x32000,
+				PartitionSignature: [16]byte{0x3f, 0x9f, 0xd9, 0x3c, 0x2b, 0x4b, 0xeb, 0x43, 0xac, 0x29, 0xf0, 0x89, 0x0a, 0x47, 0x72, 0xb7},
+				PartitionFormat:    GUIDPartitionFormat,
+				SignatureType:      GUIDSignatureType,
+			},
+			`{"type":"Media","subType":"HardDrive",` +
+				`"text":"HD(1,GPT,3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,0x800,0x32000)",` +
+				`"fields":{"PartitionNumber":1,"PartitionStartLBA":2048,"PartitionSizeLBA":204800,` +
+				`"PartitionSignature":"3f9fd93c2b4beb43ac29f0890a4772b7","PartitionFormat":2,"SignatureType":2}}`,
+		},
+		{
+			"file path",
+			&FilePathDevicePath{Head: Head{Type: MediaType, SubType: FilePathSubType, Length: 10}, PathName: []byte{'\\', 0, 'a', 0, 0, 0}},
+			`{"type":"Media","subType":"FilePath","length":10,"text":"File(\\a)","fields":{"PathName":"\\a"}}`,
+		},
+		{
+			"vendor hardware",
+			&VendorHardwareDevicePath{VendorGUID: efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7"), VendorDefinedData: []byte{0xaa}},
+			`{"type":"Hardware","subType":"Vendor","vendor":"3CD99F3F-4B2B-43EB-AC29-F0890A4772B7",` +
+				`"text":"VenHw(3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,aa)",` +
+				`"fields":{"VendorGUID":"3CD99F3F-4B2B-43EB-AC29-F0890A4772B7","VendorDefinedData":"aa"}}`,
+		},
+		{
+			"dns",
+			&DNSDevicePath{DNSServerIPs: [][16]byte{{10, 0, 0, 1}}},
+			`{"type":"Messaging","subType":"DNS","text":"Dns(10.0.0.1)",` +
+				`"fields":{"IsIPv6":false,"DNSServerIPs":["0a000001000000000000000000000000"]}}`,
+		},
+		{
+			"uart flow control",
+			&UARTFlowControlDevicePath{FlowControlMap: UARTFlowControlHardware},
+			`{"type":"Messaging","subType":"Vendor","vendor":"37499A9D-542F-4C89-A026-35DA142094E4",` +
+				`"text":"UartFlowCtrl(Hardware)","fields":{"FlowControlMap":1}}`,
+		},
+		{
+			"registered vendor node",
+			&testVendorDevicePath{Value: 0x1234},
+			`{"type":"Hardware","subType":"Vendor","text":"TestVendor(4660)","data":"b2a4e30f358da44ba5a35e3e1f0d5c213412"}`,
+		},
+		{
+			"unrecognized",
+			&UnrecognizedDevicePath{Head: Head{Type: 128, SubType: 1, Length: 9}, Data: []byte{0x01, 0x23, 0x45, 0x67, 0x89}},
+			`{"type":"128","subType":"1","length":9,"text":"Path(128,1,0123456789)","data":"0123456789"}`,
+		},
+		{
+			"end",
+			&EndOfPath{},
+			`{"type":"End","subType":"EndEntire","fields":{}}`,
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := MarshalNodeJSON(tc.inp)
+			if err != nil {
+				t.Fatalf("MarshalNodeJSON() error = %v", err)
+			}
+			if string(got) != tc.want {
+				t.Errorf("MarshalNodeJSON() = %s, want %s", got, tc.want)
+			}
+
+			d, err := UnmarshalNodeJSON(got)
+			if err != nil {
+				t.Fatalf("UnmarshalNodeJSON() error = %v", err)
+			}
+
+			var want, buf bytes.Buffer
+			if _, err := tc.inp.WriteTo(&want); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if _, err := d.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), want.Bytes()) {
+				t.Errorf("UnmarshalNodeJSON() WriteTo() = %x, want %x", buf.Bytes(), want.Bytes())
+			}
+		})
+	}
+}
+
+func TestUnmarshalNodeJSON_Errors(t *testing.T) {
+	tt := []struct {
+		name    string
+		inp     string
+		wantErr error
+	}{
+		{"unknown node", `{"type":"Media","subType":"200","fields":{}}`, ErrUnknownNode},
+		{"unknown field", `{"type":"Media","subType":"FilePath","fields":{"Foo":1}}`, ErrUnknownField},
+		{"head field", `{"type":"Media","subType":"FilePath","fields":{"Head":{}}}`, ErrUnknownField},
+		{"oversized data", `{"type":"128","subType":"1","data":"` + string(bytes.Repeat([]byte("00"), 0xffff)) + `"}`, ErrNodeTooLarge},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := UnmarshalNodeJSON([]byte(tc.inp)); !errors.Is(err, tc.wantErr) {
+				t.Errorf("UnmarshalNodeJSON() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	for _, inp := range []string{
+		`{"type":"Media","subType":"FilePath"}`,
+		`{"type":"Foo","subType":"4","data":""}`,
+		`{"type":"Media","subType":"Foo","data":""}`,
+		`{"type":"128","subType":"1","data":"0"}`,
+		`{"type":"Media","subType":"HardDrive","fields":{"PartitionSignature":"00"}}`,
+		`{"type":"Messaging","subType":"Vendor","vendor":"37499A9D-542F-4C89-A026-35DA142094E4","fields":{"VendorGUID":"37499A9D-542F-4C89-A026-35DA142094E4"}}`,
+	} {
+		if _, err := UnmarshalNodeJSON([]byte(inp)); err == nil {
+			t.Errorf("UnmarshalNodeJSON(%s) succeeded, want error", inp)
+		}
+	}
+}
+
+func TestMarshalNodeJSON_Malformed(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+	}{
+		{"file path with unpaired surrogate", "04040800 00d80000"},
+		{"uri outside of ascii", "03180500 ff"},
+		{"usb wwid with unpaired surrogate", "03100c00 0000 0000 0000 00dc"},
+		{"bbs description outside of ascii", "05010a00 0500 0000 ff00"},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inp := mustDecodeHex(t, tc.inp)
+
+			var p DevicePaths
+			if _, err := p.ReadFrom(bytes.NewReader(append(inp, 0x7f, 0xff, 0x04, 0x00))); err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+
+			b, err := MarshalNodeJSON(p[0])
+			if err != nil {
+				t.Fatalf("MarshalNodeJSON() error = %v", err)
+			}
+			if !bytes.Contains(b, []byte(`"data":`)) {
+				t.Errorf("MarshalNodeJSON() = %s, want data", b)
+			}
+
+			d, err := UnmarshalNodeJSON(b)
+			if err != nil {
+				t.Fatalf("UnmarshalNodeJSON() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if _, err := d.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), inp) {
+				t.Errorf("UnmarshalNodeJSON() WriteTo() = %x, want %x", buf.Bytes(), inp)
+			}
+		})
+	}
+}
+
+func TestDevicePaths_JSON(t *testing.T) {
+	inp := mustDecodeHex(t, "04012a00 01000000 0008000000000000 0020030000000000 ffffffffffffffffffffffffffffffff 0202"+
+		"04040a005c00610000007f010400 8001050001 7fff0400 010106000001 7fff0400")
+
+	p, err := DecodeList(inp, DecodeOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("DecodeList() error = %v", err)
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var got DevicePaths
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal(%s) error = %v", b, err)
+	}
+	if len(got) != len(p) {
+		t.Errorf("Unmarshal(%s) = %d nodes, want %d", b, len(got), len(p))
+	}
+
+	var buf bytes.Buffer
+	if _, err := got.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), inp) {
+		t.Errorf("Unmarshal(%s) WriteTo() = %x, want %x", b, buf.Bytes(), inp)
+	}
+}
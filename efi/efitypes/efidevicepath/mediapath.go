@@ -20,8 +20,8 @@ import (
 	"io"
 
 	"github.com/0x5a17ed/uefi/efi/efiguid"
-	"github.com/0x5a17ed/uefi/efi/efihex"
 	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
 )
 
 //go:generate go run github.com/hexaflex/stringer -type=PartitionFormat,SignatureType -output mediapath_string.go
@@ -101,22 +101,21 @@ func (p *HardDriveMediaDevicePath) GetHead() *Head {
 }
 
 func (p *HardDriveMediaDevicePath) Text() string {
-	var t, sig, r string
+	var t, sig string
 
-	switch p.PartitionFormat {
-	case PCATPartitionFormat:
+	switch p.SignatureType {
+	case PCATSignatureType:
 		t = "MBR"
-		sig = fmt.Sprintf("%#08x", binary.LittleEndian.Uint32(p.PartitionSignature[:]))
-	case GUIDPartitionFormat:
+		sig = fmt.Sprintf("0x%08x", binary.LittleEndian.Uint32(p.PartitionSignature[:]))
+	case GUIDSignatureType:
 		t = "GPT"
 		sig = (efiguid.GUID)(p.PartitionSignature).String()
+	default:
+		t = fmt.Sprintf("%d", p.SignatureType)
+		sig = "0"
 	}
 
-	if p.PartitionNumber != 0 {
-		r = fmt.Sprintf(",%#x,%#x", p.PartitionStartLBA, p.PartitionSizeLBA)
-	}
-
-	return fmt.Sprintf("HD(%d,%s,%s%s)", p.PartitionNumber, t, sig, r)
+	return fmt.Sprintf("HD(%d,%s,%s,%#x,%#x)", p.PartitionNumber, t, sig, p.PartitionStartLBA, p.PartitionSizeLBA)
 }
 
 func (p *HardDriveMediaDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
@@ -131,6 +130,20 @@ func (p *HardDriveMediaDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
 	)
 }
 
+func (p *HardDriveMediaDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		MediaType,
+		HardDriveSubType,
+		p.PartitionNumber,
+		p.PartitionStartLBA,
+		p.PartitionSizeLBA,
+		p.PartitionSignature,
+		p.PartitionFormat,
+		p.SignatureType,
+	)
+}
+
 // CDROMDevicePath defines a system partition that exists on a CD-ROM.
 //
 // Section 10.3.5.2
@@ -155,12 +168,16 @@ func (p *CDROMDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
 	return efireader.ReadFields(r, &p.BootEntry, &p.PartitionStartRBA, &p.PartitionSize)
 }
 
+func (p *CDROMDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, CDROMSubType, p.BootEntry, p.PartitionStartRBA, p.PartitionSize)
+}
+
 func (p *CDROMDevicePath) GetHead() *Head {
 	return &p.Head
 }
 
 func (p *CDROMDevicePath) Text() string {
-	return fmt.Sprintf("CDROM(%d,%x,%x)", p.BootEntry, p.PartitionStartRBA, p.PartitionSize)
+	return fmt.Sprintf("CDROM(%#x,%#x,%#x)", p.BootEntry, p.PartitionStartRBA, p.PartitionSize)
 }
 
 // VendorMediaDevicePath describes a file path node.
@@ -178,7 +195,7 @@ type VendorMediaDevicePath struct {
 }
 
 func (p *VendorMediaDevicePath) Text() string {
-	return fmt.Sprintf("VenMedia(%s,%s)", p.VendorGUID, efihex.EncodeToString(p.VendorDefinedData))
+	return vendorText("VenMedia", p.VendorGUID, p.VendorDefinedData)
 }
 
 func (p *VendorMediaDevicePath) GetHead() *Head {
@@ -196,17 +213,48 @@ func (p *VendorMediaDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
 	return
 }
 
+func (p *VendorMediaDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, VendorMediaSubType, p.VendorGUID, p.VendorDefinedData)
+}
+
 // FilePathDevicePath describes a file path node.
 type FilePathDevicePath struct {
 	Head
 
-	PathName []byte
+	// PathName is the null terminated UCS-2 encoded path name.
+	PathName []byte `efijson:"utf16z"`
+}
+
+// NewFilePathDevicePath returns a File Path node for the given path
+// name, which has to be representable as UCS-2 string.
+func NewFilePathDevicePath(name string) (*FilePathDevicePath, error) {
+	b, err := efireader.EncodeUTF16Z(name, efireader.UCS2)
+	if err != nil {
+		return nil, fmt.Errorf("efi/devicepath: path name: %w", err)
+	}
+	return &FilePathDevicePath{PathName: b}, nil
+}
+
+// DecodePathName returns the path name decoded as a null terminated
+// UCS-2 string.  Unlike Text it fails on invalid path names.
+func (f *FilePathDevicePath) DecodePathName() (string, error) {
+	s, err := efireader.DecodeUTF16Z(f.PathName, efireader.UCS2)
+	if err != nil {
+		return "", fmt.Errorf("efi/devicepath: path name: %w", err)
+	}
+	return s, nil
 }
 
 func (f *FilePathDevicePath) Text() string {
 	return fmt.Sprintf("File(%s)", efireader.UTF16ZBytesToString(f.PathName))
 }
 
+// FormatText returns the bare path name, as ConvertDevicePathToText
+// does regardless of flags.
+func (f *FilePathDevicePath) FormatText(TextFlags) string {
+	return efireader.UTF16ZBytesToString(f.PathName)
+}
+
 func (p *FilePathDevicePath) GetHead() *Head {
 	return &p.Head
 }
@@ -217,6 +265,269 @@ func (p *FilePathDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
 	return
 }
 
+func (p *FilePathDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, FilePathSubType, efiwriter.TerminateUTF16(p.PathName))
+}
+
+// MediaProtocolDevicePath identifies the protocol used to access a
+// medium.
+//
+// Section 10.3.5.6 "Media Protocol Device Path"
+type MediaProtocolDevicePath struct {
+	Head
+
+	// Protocol is the GUID of the protocol used to access the
+	// medium.
+	Protocol efiguid.GUID
+}
+
+func (p *MediaProtocolDevicePath) Text() string {
+	return fmt.Sprintf("Media(%s)", p.Protocol)
+}
+
+func (p *MediaProtocolDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *MediaProtocolDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Protocol)
+}
+
+func (p *MediaProtocolDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, MediaProtocolSubType, p.Protocol)
+}
+
+// FirmwareFileDevicePath identifies a file inside a firmware volume
+// as defined by the PI specification.
+//
+// Section 10.3.5.7 "PIWG Firmware File"
+type FirmwareFileDevicePath struct {
+	Head
+
+	// FirmwareFileName is the name of the firmware file.
+	FirmwareFileName efiguid.GUID
+}
+
+func (p *FirmwareFileDevicePath) Text() string {
+	return fmt.Sprintf("FvFile(%s)", p.FirmwareFileName)
+}
+
+func (p *FirmwareFileDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *FirmwareFileDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.FirmwareFileName)
+}
+
+func (p *FirmwareFileDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, PIWGFirmwareFileSubType, p.FirmwareFileName)
+}
+
+// FirmwareVolumeDevicePath identifies a firmware volume as defined
+// by the PI specification.
+//
+// Section 10.3.5.8 "PIWG Firmware Volume"
+type FirmwareVolumeDevicePath struct {
+	Head
+
+	// FirmwareVolumeName is the name of the firmware volume.
+	FirmwareVolumeName efiguid.GUID
+}
+
+func (p *FirmwareVolumeDevicePath) Text() string {
+	return fmt.Sprintf("Fv(%s)", p.FirmwareVolumeName)
+}
+
+func (p *FirmwareVolumeDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *FirmwareVolumeDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.FirmwareVolumeName)
+}
+
+func (p *FirmwareVolumeDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, PIWGFirmwareVolumeSubType, p.FirmwareVolumeName)
+}
+
+// RelativeOffsetRangeDevicePath defines a range of the data
+// described by the preceding Device Path nodes.
+//
+// Section 10.3.5.9 "Relative Offset Range"
+type RelativeOffsetRangeDevicePath struct {
+	Head
+
+	Reserved uint32
+
+	// StartingOffset is the offset of the first byte.
+	StartingOffset uint64
+
+	// EndingOffset is the offset of the last byte.
+	EndingOffset uint64
+}
+
+func (p *RelativeOffsetRangeDevicePath) Text() string {
+	return fmt.Sprintf("Offset(%#x,%#x)", p.StartingOffset, p.EndingOffset)
+}
+
+func (p *RelativeOffsetRangeDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *RelativeOffsetRangeDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Reserved, &p.StartingOffset, &p.EndingOffset)
+}
+
+func (p *RelativeOffsetRangeDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, RelativeOffsetRangeSubType, p.Reserved, p.StartingOffset, p.EndingOffset)
+}
+
+// ramDiskNames maps the well-known RAM disk types to the names of
+// their text representations.
+var ramDiskNames = map[efiguid.GUID]string{
+	VirtualDiskGUID:           "VirtualDisk",
+	VirtualCDGUID:             "VirtualCD",
+	PersistentVirtualDiskGUID: "PersistentVirtualDisk",
+	PersistentVirtualCDGUID:   "PersistentVirtualCD",
+}
+
+// RAMDiskDevicePath defines a RAM disk.
+//
+// Section 10.3.5.10 "RAM Disk"
+type RAMDiskDevicePath struct {
+	Head
+
+	// StartingAddress is the address of the first byte of the
+	// RAM disk.
+	StartingAddress uint64
+
+	// EndingAddress is the address of the last byte of the RAM
+	// disk.
+	EndingAddress uint64
+
+	// DiskType identifies the type of the RAM disk, e.g.
+	// VirtualDiskGUID.
+	DiskType efiguid.GUID
+
+	// DiskInstance is the instance number of the RAM disk.
+	DiskInstance uint16
+}
+
+func (p *RAMDiskDevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *RAMDiskDevicePath) FormatText(flags TextFlags) string {
+	if name, ok := ramDiskNames[p.DiskType]; ok && flags&AllowShortcuts != 0 {
+		return fmt.Sprintf("%s(%#x,%#x,%d)", name, p.StartingAddress, p.EndingAddress, p.DiskInstance)
+	}
+	return fmt.Sprintf("RamDisk(%#x,%#x,%d,%s)", p.StartingAddress, p.EndingAddress, p.DiskInstance, p.DiskType)
+}
+
+func (p *RAMDiskDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *RAMDiskDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.StartingAddress, &p.EndingAddress, &p.DiskType, &p.DiskInstance)
+}
+
+func (p *RAMDiskDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, RAMDiskSubType, p.StartingAddress, p.EndingAddress, p.DiskType, p.DiskInstance)
+}
+
+// parseHardDriveText parses HD(Partition,Type,Signature,Start,Size).
+func parseHardDriveText(a *textArgs) DevicePath {
+	p := &HardDriveMediaDevicePath{
+		PartitionNumber:   a.u32(0),
+		PartitionStartLBA: a.u64(3),
+		PartitionSizeLBA:  a.u64(4),
+	}
+
+	switch a.str(1) {
+	case "MBR":
+		p.PartitionFormat = PCATPartitionFormat
+		p.SignatureType = PCATSignatureType
+		binary.LittleEndian.PutUint32(p.PartitionSignature[:], a.u32(2))
+	case "GPT":
+		p.PartitionFormat = GUIDPartitionFormat
+		p.SignatureType = GUIDSignatureType
+		p.PartitionSignature = a.guid(2)
+	default:
+		p.SignatureType = SignatureType(a.u8(1))
+	}
+	return p
+}
+
+// parseCDROMText parses CDROM(Entry,Start,Size).
+func parseCDROMText(a *textArgs) DevicePath {
+	return &CDROMDevicePath{
+		BootEntry:         a.u32(0),
+		PartitionStartRBA: a.u64(1),
+		PartitionSize:     a.u64(2),
+	}
+}
+
+// parseFilePathText parses File(Path).  Like EDK2 the whole parameter
+// list is taken as path, which may contain commas.
+func parseFilePathText(a *textArgs) DevicePath {
+	b, err := efireader.EncodeUTF16Z(a.all(), efireader.UCS2)
+	if err != nil {
+		a.fail(0, err)
+	}
+	return &FilePathDevicePath{PathName: b}
+}
+
+// parseMediaProtocolText parses Media(GUID).
+func parseMediaProtocolText(a *textArgs) DevicePath {
+	return &MediaProtocolDevicePath{Protocol: a.guid(0)}
+}
+
+// parseFirmwareFileText parses FvFile(GUID).
+func parseFirmwareFileText(a *textArgs) DevicePath {
+	return &FirmwareFileDevicePath{FirmwareFileName: a.guid(0)}
+}
+
+// parseFirmwareVolumeText parses Fv(GUID).
+func parseFirmwareVolumeText(a *textArgs) DevicePath {
+	return &FirmwareVolumeDevicePath{FirmwareVolumeName: a.guid(0)}
+}
+
+// parseRelativeOffsetRangeText parses Offset(StartingOffset,EndingOffset).
+func parseRelativeOffsetRangeText(a *textArgs) DevicePath {
+	return &RelativeOffsetRangeDevicePath{StartingOffset: a.u64(0), EndingOffset: a.u64(1)}
+}
+
+// parseRAMDiskText parses RamDisk(StartingAddress,EndingAddress,DiskInstance,DiskType).
+func parseRAMDiskText(a *textArgs) DevicePath {
+	return &RAMDiskDevicePath{
+		StartingAddress: a.u64(0),
+		EndingAddress:   a.u64(1),
+		DiskInstance:    a.u16(2),
+		DiskType:        a.guid(3),
+	}
+}
+
+// ramDiskTextParser returns a parser for the text representation of
+// the well-known RAM disk type g, i.e.
+// VirtualDisk(StartingAddress,EndingAddress,DiskInstance).  Without
+// arguments, VirtualDisk() and VirtualCD() are parsed as
+// VirtualMediaDevicePath instead.
+func ramDiskTextParser(g efiguid.GUID) textParseFn {
+	return func(a *textArgs) DevicePath {
+		if _, ok := virtualMediaNames[g]; ok && len(a.args) == 0 {
+			return &VirtualMediaDevicePath{DiskType: g}
+		}
+		return &RAMDiskDevicePath{
+			StartingAddress: a.u64(0),
+			EndingAddress:   a.u64(1),
+			DiskInstance:    a.u16(2),
+			DiskType:        g,
+		}
+	}
+}
+
 const (
 	_ DevicePathSubType = iota
 
@@ -230,13 +541,23 @@ const (
 	VendorMediaSubType
 
 	FilePathSubType
+
+	// MediaProtocolSubType identifies the protocol used to access
+	// a medium.
 	MediaProtocolSubType
 
+	// PIWGFirmwareFileSubType identifies a file inside a firmware
+	// volume.
 	PIWGFirmwareFileSubType
+
+	// PIWGFirmwareVolumeSubType identifies a firmware volume.
 	PIWGFirmwareVolumeSubType
 
+	// RelativeOffsetRangeSubType defines a range of the data
+	// described by the preceding nodes.
 	RelativeOffsetRangeSubType
 
+	// RAMDiskSubType defines a RAM disk.
 	RAMDiskSubType
 )
 
@@ -247,9 +568,22 @@ func ParseMediaDevicePath(f io.Reader, h Head) (p DevicePath, err error) {
 	case CDROMSubType:
 		p = &CDROMDevicePath{Head: h}
 	case VendorMediaSubType:
-		p = &VendorMediaDevicePath{Head: h}
+		if p, err = parseVendorNode(f, h, &VendorMediaDevicePath{Head: h}); err != nil {
+			return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", h.Type, h.SubType, err)
+		}
+		return
 	case FilePathSubType:
 		p = &FilePathDevicePath{Head: h}
+	case MediaProtocolSubType:
+		p = &MediaProtocolDevicePath{Head: h}
+	case PIWGFirmwareFileSubType:
+		p = &FirmwareFileDevicePath{Head: h}
+	case PIWGFirmwareVolumeSubType:
+		p = &FirmwareVolumeDevicePath{Head: h}
+	case RelativeOffsetRangeSubType:
+		p = &RelativeOffsetRangeDevicePath{Head: h}
+	case RAMDiskSubType:
+		p = &RAMDiskDevicePath{Head: h}
 	default:
 		p = &UnrecognizedDevicePath{Head: h}
 	}
@@ -259,3 +593,9 @@ func ParseMediaDevicePath(f io.Reader, h Head) (p DevicePath, err error) {
 	}
 	return
 }
+
+func init() {
+	for g, name := range ramDiskNames {
+		textParsers[name] = ramDiskTextParser(g)
+	}
+}
@@ -15,9 +15,11 @@
 package efidevicepath
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
 )
 
 func TestHardDriveMediaDevicePath_Notation(t *testing.T) {
@@ -37,7 +39,7 @@ func TestHardDriveMediaDevicePath_Notation(t *testing.T) {
 		{
 			"mbr, whole disk",
 			fields{0, 0, 0, [16]byte{}, PCATPartitionFormat, PCATSignatureType},
-			"HD(0,MBR,0x00000000)",
+			"HD(0,MBR,0x00000000,0x0,0x0)",
 		},
 		{
 			"mbr, first part",
@@ -66,3 +68,68 @@ func TestHardDriveMediaDevicePath_Notation(t *testing.T) {
 		})
 	}
 }
+
+func TestMediaDevicePath(t *testing.T) {
+	runNodeTests(t, []nodeTestCase{
+		{
+			"media protocol",
+			&MediaProtocolDevicePath{Protocol: efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7")},
+			"Media(3CD99F3F-4B2B-43EB-AC29-F0890A4772B7)",
+			"04051400 3f9fd93c2b4beb43ac29f0890a4772b7",
+		},
+		{
+			"firmware file",
+			&FirmwareFileDevicePath{FirmwareFileName: efiguid.MustFromString("7c04a583-9e3e-4f1c-ad65-e05268d0b4d1")},
+			"FvFile(7C04A583-9E3E-4F1C-AD65-E05268D0B4D1)",
+			"04061400 83a5047c3e9e1c4fad65e05268d0b4d1",
+		},
+		{
+			"firmware volume",
+			&FirmwareVolumeDevicePath{FirmwareVolumeName: efiguid.MustFromString("8c8ce578-8a3d-4f1c-9935-896185c32dd3")},
+			"Fv(8C8CE578-8A3D-4F1C-9935-896185C32DD3)",
+			"04071400 78e58c8c3d8a1c4f9935896185c32dd3",
+		},
+		{
+			"relative offset range",
+			&RelativeOffsetRangeDevicePath{StartingOffset: 0x1000, EndingOffset: 0x1fff},
+			"Offset(0x1000,0x1fff)",
+			"04081800 00000000 0010000000000000 ff1f000000000000",
+		},
+		{
+			"virtual cd",
+			&RAMDiskDevicePath{StartingAddress: 0x7f000000, EndingAddress: 0x7fffffff, DiskType: VirtualCDGUID},
+			"VirtualCD(0x7f000000,0x7fffffff,0)",
+			"04092600 0000007f00000000 ffffff7f00000000 30bd5a3d7541ce876d64d2ade523c4bb 0000",
+		},
+		{
+			"ram disk",
+			&RAMDiskDevicePath{
+				StartingAddress: 0x1000,
+				EndingAddress:   0x1fff,
+				DiskType:        efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7"),
+				DiskInstance:    1,
+			},
+			"RamDisk(0x1000,0x1fff,1,3CD99F3F-4B2B-43EB-AC29-F0890A4772B7)",
+			"04092600 0010000000000000 ff1f000000000000 3f9fd93c2b4beb43ac29f0890a4772b7 0100",
+		},
+	})
+}
+
+func TestNewFilePathDevicePath(t *testing.T) {
+	f, err := NewFilePathDevicePath(`\EFI\BOOT\BOOTX64.EFI`)
+	if err != nil {
+		t.Fatalf("NewFilePathDevicePath() error = %v", err)
+	}
+	if got, err := f.DecodePathName(); err != nil || got != `\EFI\BOOT\BOOTX64.EFI` {
+		t.Errorf("DecodePathName() = %q, %v", got, err)
+	}
+
+	if _, err := NewFilePathDevicePath("\U0001F600"); !errors.Is(err, efireader.ErrNotUCS2) {
+		t.Errorf("NewFilePathDevicePath() error = %v, want %v", err, efireader.ErrNotUCS2)
+	}
+
+	f = &FilePathDevicePath{PathName: []byte{'a', 0x00}}
+	if _, err := f.DecodePathName(); !errors.Is(err, efireader.ErrMissingTerminator) {
+		t.Errorf("DecodePathName() error = %v, want %v", err, efireader.ErrMissingTerminator)
+	}
+}
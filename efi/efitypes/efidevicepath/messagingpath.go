@@ -15,11 +15,814 @@
 package efidevicepath
 
 import (
+	"encoding/binary"
+	"fmt"
 	"io"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+const (
+	// ATAPISubType defines the path to an ATAPI device.
+	//
+	// Section 10.3.4.1 "ATAPI Device Path"
+	ATAPISubType DevicePathSubType = 1
+
+	// SCSISubType defines the path to a SCSI device.
+	SCSISubType DevicePathSubType = 2
+
+	// FibreChannelSubType defines the path to a Fibre Channel
+	// device.
+	FibreChannelSubType DevicePathSubType = 3
+
+	// IEEE1394SubType defines the path to an IEEE 1394 device.
+	IEEE1394SubType DevicePathSubType = 4
+
+	// USBSubType defines the path to a USB device.
+	USBSubType DevicePathSubType = 5
+
+	// I2OSubType defines the path to an I2O device.
+	I2OSubType DevicePathSubType = 6
+
+	// InfiniBandSubType defines the path to an InfiniBand device.
+	InfiniBandSubType DevicePathSubType = 9
+
+	// VendorMessagingSubType is a vendor-defined messaging
+	// Device Path.
+	VendorMessagingSubType DevicePathSubType = 10
+
+	// MACAddressSubType defines the MAC address of a network
+	// interface.
+	MACAddressSubType DevicePathSubType = 11
+
+	// IPv4SubType defines an IPv4 network connection.
+	IPv4SubType DevicePathSubType = 12
+
+	// IPv6SubType defines an IPv6 network connection.
+	IPv6SubType DevicePathSubType = 13
+
+	// UARTSubType defines the settings of a UART.
+	UARTSubType DevicePathSubType = 14
+
+	// USBClassSubType defines a USB device by its class.
+	USBClassSubType DevicePathSubType = 15
+
+	// USBWWIDSubType defines a USB device by its serial number.
+	USBWWIDSubType DevicePathSubType = 16
+
+	// DeviceLogicalUnitSubType defines the logical unit of a
+	// device supporting multiple logical units.
+	DeviceLogicalUnitSubType DevicePathSubType = 17
+
+	// SATASubType defines the path to a SATA device.
+	SATASubType DevicePathSubType = 18
+
+	// ISCSISubType defines an iSCSI target.
+	ISCSISubType DevicePathSubType = 19
+
+	// VLANSubType defines a VLAN of a network interface.
+	VLANSubType DevicePathSubType = 20
+
+	// FibreChannelExSubType defines the path to a Fibre Channel
+	// device using byte arrays for its addresses.
+	FibreChannelExSubType DevicePathSubType = 21
+
+	// SASExSubType defines the path to a SAS device.
+	SASExSubType DevicePathSubType = 22
+
+	// NVMeNamespaceSubType defines the path to an NVM Express
+	// namespace.
+	NVMeNamespaceSubType DevicePathSubType = 23
+
+	// URISubType defines a Uniform Resource Identifier.
+	URISubType DevicePathSubType = 24
+
+	// UFSSubType defines the path to a Universal Flash Storage
+	// device.
+	UFSSubType DevicePathSubType = 25
+
+	// SDSubType defines the path to an SD card.
+	SDSubType DevicePathSubType = 26
+
+	// BluetoothSubType defines the path to a Bluetooth device.
+	BluetoothSubType DevicePathSubType = 27
+
+	// WiFiSubType defines a Wi-Fi network.
+	WiFiSubType DevicePathSubType = 28
+
+	// EMMCSubType defines the path to an eMMC device.
+	EMMCSubType DevicePathSubType = 29
+
+	// BluetoothLESubType defines the path to a Bluetooth Low
+	// Energy device.
+	BluetoothLESubType DevicePathSubType = 30
+
+	// DNSSubType defines the DNS servers of a network connection.
+	DNSSubType DevicePathSubType = 31
+
+	// NVDIMMNamespaceSubType defines the path to an NVDIMM
+	// namespace.
+	NVDIMMNamespaceSubType DevicePathSubType = 32
+
+	// RESTServiceSubType defines a REST service.
+	RESTServiceSubType DevicePathSubType = 33
 )
 
+// ATAPIDevicePath defines the path to an ATAPI device.
+//
+// Section 10.3.4.1 "ATAPI Device Path"
+type ATAPIDevicePath struct {
+	Head
+
+	// PrimarySecondary is 0 for the primary and 1 for the
+	// secondary controller.
+	PrimarySecondary uint8
+
+	// SlaveMaster is 0 for the master and 1 for the slave
+	// device.
+	SlaveMaster uint8
+
+	// LUN is the Logical Unit Number.
+	LUN uint16
+}
+
+func (p *ATAPIDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *ATAPIDevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *ATAPIDevicePath) FormatText(flags TextFlags) string {
+	if flags&DisplayOnly != 0 {
+		return fmt.Sprintf("Ata(%#x)", p.LUN)
+	}
+
+	controller, drive := "Primary", "Master"
+	if p.PrimarySecondary != 0 {
+		controller = "Secondary"
+	}
+	if p.SlaveMaster != 0 {
+		drive = "Slave"
+	}
+	return fmt.Sprintf("Ata(%s,%s,%#x)", controller, drive, p.LUN)
+}
+
+func (p *ATAPIDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.PrimarySecondary, &p.SlaveMaster, &p.LUN)
+}
+
+func (p *ATAPIDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, ATAPISubType, p.PrimarySecondary, p.SlaveMaster, p.LUN)
+}
+
+// parseATAPIText parses Ata(Controller,Drive,LUN).
+func parseATAPIText(a *textArgs) DevicePath {
+	return &ATAPIDevicePath{
+		PrimarySecondary: uint8(a.enum(0, 8, "Primary", "Secondary")),
+		SlaveMaster:      uint8(a.enum(1, 8, "Master", "Slave")),
+		LUN:              a.u16(2),
+	}
+}
+
+// SCSIDevicePath defines the path to a SCSI device.
+//
+// Section 10.3.4.2 "SCSI Device Path"
+type SCSIDevicePath struct {
+	Head
+
+	// PUN is the Target ID on the SCSI bus.
+	PUN uint16
+
+	// LUN is the Logical Unit Number.
+	LUN uint16
+}
+
+func (p *SCSIDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *SCSIDevicePath) Text() string {
+	return fmt.Sprintf("Scsi(%#x,%#x)", p.PUN, p.LUN)
+}
+
+func (p *SCSIDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.PUN, &p.LUN)
+}
+
+func (p *SCSIDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, SCSISubType, p.PUN, p.LUN)
+}
+
+// parseSCSIText parses Scsi(PUN,LUN).
+func parseSCSIText(a *textArgs) DevicePath {
+	return &SCSIDevicePath{PUN: a.u16(0), LUN: a.u16(1)}
+}
+
+// FibreChannelDevicePath defines the path to a Fibre Channel device.
+//
+// Section 10.3.4.3 "Fibre Channel Device Path"
+type FibreChannelDevicePath struct {
+	Head
+
+	Reserved uint32
+
+	// WWN is the World Wide Name of the device.
+	WWN uint64
+
+	// LUN is the Logical Unit Number.
+	LUN uint64
+}
+
+func (p *FibreChannelDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *FibreChannelDevicePath) Text() string {
+	return fmt.Sprintf("Fibre(%#x,%#x)", p.WWN, p.LUN)
+}
+
+func (p *FibreChannelDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Reserved, &p.WWN, &p.LUN)
+}
+
+func (p *FibreChannelDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, FibreChannelSubType, p.Reserved, p.WWN, p.LUN)
+}
+
+// parseFibreChannelText parses Fibre(WWN,LUN).
+func parseFibreChannelText(a *textArgs) DevicePath {
+	return &FibreChannelDevicePath{WWN: a.u64(0), LUN: a.u64(1)}
+}
+
+// FibreChannelExDevicePath defines the path to a Fibre Channel
+// device with its addresses stored as big endian byte arrays.
+//
+// Section 10.3.4.4 "Fibre Channel Ex Device Path"
+type FibreChannelExDevicePath struct {
+	Head
+
+	Reserved uint32
+
+	// WWN is the End Device Port Name of the device.
+	WWN [8]byte
+
+	// LUN is the Logical Unit Number.
+	LUN [8]byte
+}
+
+func (p *FibreChannelExDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *FibreChannelExDevicePath) Text() string {
+	return fmt.Sprintf("FibreEx(%#x,%#x)", p.WWN[:], p.LUN[:])
+}
+
+func (p *FibreChannelExDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Reserved, &p.WWN, &p.LUN)
+}
+
+func (p *FibreChannelExDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, FibreChannelExSubType, p.Reserved, p.WWN, p.LUN)
+}
+
+// parseFibreChannelExText parses FibreEx(WWN,LUN).
+func parseFibreChannelExText(a *textArgs) DevicePath {
+	return &FibreChannelExDevicePath{WWN: a.be8(0), LUN: a.be8(1)}
+}
+
+// IEEE1394DevicePath defines the path to an IEEE 1394 device.
+//
+// Section 10.3.4.5 "1394 Device Path"
+type IEEE1394DevicePath struct {
+	Head
+
+	Reserved uint32
+
+	// GUID is the 1394 Global Unique ID of the device.
+	GUID uint64
+}
+
+func (p *IEEE1394DevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *IEEE1394DevicePath) Text() string {
+	return fmt.Sprintf("I1394(%016X)", p.GUID)
+}
+
+func (p *IEEE1394DevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Reserved, &p.GUID)
+}
+
+func (p *IEEE1394DevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, IEEE1394SubType, p.Reserved, p.GUID)
+}
+
+// parseIEEE1394Text parses I1394(GUID).
+func parseIEEE1394Text(a *textArgs) DevicePath {
+	return &IEEE1394DevicePath{GUID: a.hexUint(0, 64)}
+}
+
+// SASDeviceGUID identifies the vendor-defined messaging Device Path
+// used for SAS devices.
+//
+// Section 10.3.4.20 "Serial Attached SCSI (SAS) Device Path"
+var SASDeviceGUID = efiguid.MustFromString("d487ddb4-008b-11d9-afdc-001083ffca4d")
+
+// sasTopologyText formats the device topology of SAS and SAS Ex
+// Device Path nodes as SASSATA,Location,Connect,DriveBay.
+func sasTopologyText(t uint16) string {
+	switch {
+	case t&0x0f == 0:
+		return "NoTopology,0,0,0"
+	case t&0x0f <= 2 && t&0x80 == 0:
+		sasSata, location, connect, bay := "SAS", "Internal", "Direct", "0"
+		if t&0x10 != 0 {
+			sasSata = "SATA"
+		}
+		if t&0x20 != 0 {
+			location = "External"
+		}
+		if t&0x40 != 0 {
+			connect = "Expanded"
+		}
+		if t&0x0f == 2 {
+			bay = fmt.Sprintf("%#x", (t>>8)&0xff+1)
+		}
+		return strings.Join([]string{sasSata, location, connect, bay}, ",")
+	default:
+		return fmt.Sprintf("%#x,0,0,0", t)
+	}
+}
+
+// parseSASTopologyText parses the four device topology arguments
+// starting at position i.
+func parseSASTopologyText(a *textArgs, i int) (t uint16) {
+	switch sasSata := a.str(i); sasSata {
+	case "NoTopology":
+		return 0
+	case "SAS", "SATA":
+		if bay := a.u16(i + 3); bay == 0 {
+			t = 0x01
+		} else {
+			t = 0x02 | (bay-1)<<8
+		}
+		if sasSata == "SATA" {
+			t |= 0x10
+		}
+		if a.enum(i+1, 8, "Internal", "External") != 0 {
+			t |= 0x20
+		}
+		if a.enum(i+2, 8, "Direct", "Expanded") != 0 {
+			t |= 0x40
+		}
+		return
+	default:
+		return a.u16(i)
+	}
+}
+
+// SASDevicePath defines the path to a SAS device.  It is encoded as
+// a vendor-defined messaging Device Path identified by
+// SASDeviceGUID.
+//
+// Section 10.3.4.20 "Serial Attached SCSI (SAS) Device Path"
+type SASDevicePath struct {
+	Head
+
+	Reserved uint32
+
+	// SASAddress is the SAS address of the device.
+	SASAddress uint64
+
+	// LUN is the Logical Unit Number.
+	LUN uint64
+
+	// DeviceTopology describes the device and its topology.
+	DeviceTopology uint16
+
+	// RelativeTargetPort is the relative target port.
+	RelativeTargetPort uint16
+}
+
+func (p *SASDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *SASDevicePath) Text() string {
+	return fmt.Sprintf(
+		"SAS(%#x,%#x,%#x,%s,%#x)",
+		p.SASAddress,
+		p.LUN,
+		p.RelativeTargetPort,
+		sasTopologyText(p.DeviceTopology),
+		p.Reserved,
+	)
+}
+
+// ReadFrom reads the node body following the vendor GUID.
+func (p *SASDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(
+		r,
+		&p.Reserved,
+		&p.SASAddress,
+		&p.LUN,
+		&p.DeviceTopology,
+		&p.RelativeTargetPort,
+	)
+}
+
+func (p *SASDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		MessagingType,
+		VendorMessagingSubType,
+		SASDeviceGUID,
+		p.Reserved,
+		p.SASAddress,
+		p.LUN,
+		p.DeviceTopology,
+		p.RelativeTargetPort,
+	)
+}
+
+// parseSASText parses
+// SAS(Address,LUN,RTP,SASSATA,Location,Connect,DriveBay,Reserved).
+func parseSASText(a *textArgs) DevicePath {
+	return &SASDevicePath{
+		SASAddress:         a.u64(0),
+		LUN:                a.u64(1),
+		RelativeTargetPort: a.u16(2),
+		DeviceTopology:     parseSASTopologyText(a, 3),
+		Reserved:           a.u32(7),
+	}
+}
+
+// SASExDevicePath defines the path to a SAS device with its
+// addresses stored as big endian byte arrays.
+//
+// Section 10.3.4.21 "Serial Attached SCSI (SAS) Ex Device Path"
+type SASExDevicePath struct {
+	Head
+
+	// SASAddress is the SAS address of the device.
+	SASAddress [8]byte
+
+	// LUN is the Logical Unit Number.
+	LUN [8]byte
+
+	// DeviceTopology describes the device and its topology.
+	DeviceTopology uint16
+
+	// RelativeTargetPort is the relative target port.
+	RelativeTargetPort uint16
+}
+
+func (p *SASExDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *SASExDevicePath) Text() string {
+	return fmt.Sprintf(
+		"SasEx(%#x,%#x,%#x,%s)",
+		p.SASAddress[:],
+		p.LUN[:],
+		p.RelativeTargetPort,
+		sasTopologyText(p.DeviceTopology),
+	)
+}
+
+func (p *SASExDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.SASAddress, &p.LUN, &p.DeviceTopology, &p.RelativeTargetPort)
+}
+
+func (p *SASExDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		MessagingType,
+		SASExSubType,
+		p.SASAddress,
+		p.LUN,
+		p.DeviceTopology,
+		p.RelativeTargetPort,
+	)
+}
+
+// parseSASExText parses
+// SasEx(Address,LUN,RTP,SASSATA,Location,Connect,DriveBay).
+func parseSASExText(a *textArgs) DevicePath {
+	return &SASExDevicePath{
+		SASAddress:         a.be8(0),
+		LUN:                a.be8(1),
+		RelativeTargetPort: a.u16(2),
+		DeviceTopology:     parseSASTopologyText(a, 3),
+	}
+}
+
+// DeviceLogicalUnitDevicePath defines the logical unit of a device
+// supporting multiple logical units.
+//
+// Section 10.3.4.9 "Device Logical Unit"
+type DeviceLogicalUnitDevicePath struct {
+	Head
+
+	// LUN is the Logical Unit Number.
+	LUN uint8
+}
+
+func (p *DeviceLogicalUnitDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *DeviceLogicalUnitDevicePath) Text() string {
+	return fmt.Sprintf("Unit(%#x)", p.LUN)
+}
+
+func (p *DeviceLogicalUnitDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.LUN)
+}
+
+func (p *DeviceLogicalUnitDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, DeviceLogicalUnitSubType, p.LUN)
+}
+
+// parseDeviceLogicalUnitText parses Unit(LUN).
+func parseDeviceLogicalUnitText(a *textArgs) DevicePath {
+	return &DeviceLogicalUnitDevicePath{LUN: a.u8(0)}
+}
+
+// SATADevicePath defines the path to a SATA device.
+//
+// Section 10.3.4.7 "SATA Device Path"
+type SATADevicePath struct {
+	Head
+
+	// HBAPortNumber is the HBA port number that facilitates the
+	// connection to the device or a port multiplier.
+	HBAPortNumber uint16
+
+	// PortMultiplierPortNumber is the port multiplier port
+	// number. 0xFFFF is used if the device is directly
+	// connected to the HBA.
+	PortMultiplierPortNumber uint16
+
+	// LUN is the Logical Unit Number.
+	LUN uint16
+}
+
+func (p *SATADevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *SATADevicePath) Text() string {
+	return fmt.Sprintf("Sata(%#x,%#x,%#x)", p.HBAPortNumber, p.PortMultiplierPortNumber, p.LUN)
+}
+
+func (p *SATADevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.HBAPortNumber, &p.PortMultiplierPortNumber, &p.LUN)
+}
+
+func (p *SATADevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, SATASubType, p.HBAPortNumber, p.PortMultiplierPortNumber, p.LUN)
+}
+
+// parseSATAText parses Sata(HBA,PMP,LUN).
+func parseSATAText(a *textArgs) DevicePath {
+	return &SATADevicePath{
+		HBAPortNumber:            a.u16(0),
+		PortMultiplierPortNumber: a.u16(1),
+		LUN:                      a.u16(2),
+	}
+}
+
+// NVMeNamespaceDevicePath defines the path to an NVM Express
+// namespace.
+//
+// Section 10.3.4.23 "NVM Express Namespace Device Path"
+type NVMeNamespaceDevicePath struct {
+	Head
+
+	// NamespaceID is the namespace identifier.
+	NamespaceID uint32
+
+	// EUI64 is the IEEE Extended Unique Identifier of the
+	// namespace. A value of zero indicates that it is not
+	// supported.
+	EUI64 uint64
+}
+
+func (p *NVMeNamespaceDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *NVMeNamespaceDevicePath) Text() string {
+	var eui [8]byte
+	binary.BigEndian.PutUint64(eui[:], p.EUI64)
+
+	parts := make([]string, len(eui))
+	for i, b := range eui {
+		parts[i] = fmt.Sprintf("%02x", b)
+	}
+	return fmt.Sprintf("NVMe(%#x,%s)", p.NamespaceID, strings.Join(parts, "-"))
+}
+
+func (p *NVMeNamespaceDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.NamespaceID, &p.EUI64)
+}
+
+func (p *NVMeNamespaceDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, NVMeNamespaceSubType, p.NamespaceID, p.EUI64)
+}
+
+// parseNVMeNamespaceText parses NVMe(NSID,EUI) where EUI is written
+// as eight dash separated hexadecimal bytes.
+func parseNVMeNamespaceText(a *textArgs) DevicePath {
+	p := &NVMeNamespaceDevicePath{NamespaceID: a.u32(0)}
+
+	parts := strings.Split(a.str(1), "-")
+	if len(parts) != 8 {
+		a.fail(1, ErrInvalidText)
+		return p
+	}
+
+	eui := &textArgs{args: parts}
+	for i := range parts {
+		p.EUI64 = p.EUI64<<8 | eui.hexUint(i, 8)
+	}
+	if eui.err != nil {
+		a.fail(1, eui.err)
+	}
+	return p
+}
+
+// UFSDevicePath defines the path to a Universal Flash Storage device.
+//
+// Section 10.3.4.25 "UFS (Universal Flash Storage) device"
+type UFSDevicePath struct {
+	Head
+
+	// PUN is the Target ID on the UFS interface.
+	PUN uint8
+
+	// LUN is the Logical Unit Number.
+	LUN uint8
+}
+
+func (p *UFSDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *UFSDevicePath) Text() string {
+	return fmt.Sprintf("UFS(%#x,%#x)", p.PUN, p.LUN)
+}
+
+func (p *UFSDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.PUN, &p.LUN)
+}
+
+func (p *UFSDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, UFSSubType, p.PUN, p.LUN)
+}
+
+// parseUFSText parses UFS(PUN,LUN).
+func parseUFSText(a *textArgs) DevicePath {
+	return &UFSDevicePath{PUN: a.u8(0), LUN: a.u8(1)}
+}
+
+// SDDevicePath defines the path to an SD card.
+//
+// Section 10.3.4.26 "SD (Secure Digital) Device Path"
+type SDDevicePath struct {
+	Head
+
+	// SlotNumber is the slot number of the SD card.
+	SlotNumber uint8
+}
+
+func (p *SDDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *SDDevicePath) Text() string {
+	return fmt.Sprintf("SD(%#x)", p.SlotNumber)
+}
+
+func (p *SDDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.SlotNumber)
+}
+
+func (p *SDDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, SDSubType, p.SlotNumber)
+}
+
+// parseSDText parses SD(Slot).
+func parseSDText(a *textArgs) DevicePath {
+	return &SDDevicePath{SlotNumber: a.u8(0)}
+}
+
+// EMMCDevicePath defines the path to an eMMC device.
+//
+// Section 10.3.4.29 "eMMC (Embedded Multi-Media Card) Device Path"
+type EMMCDevicePath struct {
+	Head
+
+	// SlotNumber is the slot number of the eMMC device.
+	SlotNumber uint8
+}
+
+func (p *EMMCDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *EMMCDevicePath) Text() string {
+	return fmt.Sprintf("eMMC(%#x)", p.SlotNumber)
+}
+
+func (p *EMMCDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.SlotNumber)
+}
+
+func (p *EMMCDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, EMMCSubType, p.SlotNumber)
+}
+
+// parseEMMCText parses eMMC(Slot).
+func parseEMMCText(a *textArgs) DevicePath {
+	return &EMMCDevicePath{SlotNumber: a.u8(0)}
+}
+
 func ParseMessagingDevicePath(r io.Reader, h Head) (p DevicePath, err error) {
-	p = &UnrecognizedDevicePath{Head: h}
-	_, err = p.ReadFrom(r)
+	switch h.SubType {
+	case ATAPISubType:
+		p = &ATAPIDevicePath{Head: h}
+	case SCSISubType:
+		p = &SCSIDevicePath{Head: h}
+	case FibreChannelSubType:
+		p = &FibreChannelDevicePath{Head: h}
+	case FibreChannelExSubType:
+		p = &FibreChannelExDevicePath{Head: h}
+	case IEEE1394SubType:
+		p = &IEEE1394DevicePath{Head: h}
+	case USBSubType:
+		p = &USBDevicePath{Head: h}
+	case InfiniBandSubType:
+		p = &InfiniBandDevicePath{Head: h}
+	case VendorMessagingSubType:
+		if p, err = parseVendorNode(r, h, &VendorMessagingDevicePath{Head: h}); err != nil {
+			return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", h.Type, h.SubType, err)
+		}
+		return
+	case MACAddressSubType:
+		p = &MACAddressDevicePath{Head: h}
+	case IPv4SubType:
+		p = &IPv4DevicePath{Head: h}
+	case IPv6SubType:
+		p = &IPv6DevicePath{Head: h}
+	case ISCSISubType:
+		p = &ISCSIDevicePath{Head: h}
+	case VLANSubType:
+		p = &VLANDevicePath{Head: h}
+	case SASExSubType:
+		p = &SASExDevicePath{Head: h}
+	case USBClassSubType:
+		p = &USBClassDevicePath{Head: h}
+	case USBWWIDSubType:
+		p = &USBWWIDDevicePath{Head: h}
+	case DeviceLogicalUnitSubType:
+		p = &DeviceLogicalUnitDevicePath{Head: h}
+	case SATASubType:
+		p = &SATADevicePath{Head: h}
+	case NVMeNamespaceSubType:
+		p = &NVMeNamespaceDevicePath{Head: h}
+	case UFSSubType:
+		p = &UFSDevicePath{Head: h}
+	case SDSubType:
+		p = &SDDevicePath{Head: h}
+	case URISubType:
+		p = &URIDevicePath{Head: h}
+	case BluetoothSubType:
+		p = &BluetoothDevicePath{Head: h}
+	case WiFiSubType:
+		p = &WiFiDevicePath{Head: h}
+	case EMMCSubType:
+		p = &EMMCDevicePath{Head: h}
+	case BluetoothLESubType:
+		p = &BluetoothLEDevicePath{Head: h}
+	case DNSSubType:
+		p = &DNSDevicePath{Head: h}
+	case RESTServiceSubType:
+		p = &RESTServiceDevicePath{Head: h}
+	default:
+		p = &UnrecognizedDevicePath{Head: h}
+	}
+
+	if _, err := p.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("efi/devicepath: type %d-%d: %w", h.Type, h.SubType, err)
+	}
 	return
 }
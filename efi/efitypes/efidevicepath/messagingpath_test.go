@@ -0,0 +1,127 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"testing"
+)
+
+func TestMessagingDevicePath_Storage(t *testing.T) {
+	runNodeTests(t, []nodeTestCase{
+		{
+			"ata",
+			&ATAPIDevicePath{PrimarySecondary: 1, SlaveMaster: 0, LUN: 2},
+			"Ata(Secondary,Master,0x2)",
+			"03010800 01 00 0200",
+		},
+		{
+			"scsi",
+			&SCSIDevicePath{PUN: 1, LUN: 0},
+			"Scsi(0x1,0x0)",
+			"03020800 0100 0000",
+		},
+		{
+			"fibre",
+			&FibreChannelDevicePath{WWN: 0x1122334455667788, LUN: 1},
+			"Fibre(0x1122334455667788,0x1)",
+			"03031800 00000000 8877665544332211 0100000000000000",
+		},
+		{
+			"fibre ex",
+			&FibreChannelExDevicePath{
+				WWN: [8]byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88},
+				LUN: [8]byte{0, 0, 0, 0, 0, 0, 0, 1},
+			},
+			"FibreEx(0x1122334455667788,0x0000000000000001)",
+			"03151800 00000000 1122334455667788 0000000000000001",
+		},
+		{
+			"1394",
+			&IEEE1394DevicePath{GUID: 0x0011223344556677},
+			"I1394(0011223344556677)",
+			"03041000 00000000 7766554433221100",
+		},
+		{
+			"sas",
+			&SASDevicePath{SASAddress: 0x5000c50012345678, LUN: 0, DeviceTopology: 0x0212, RelativeTargetPort: 1},
+			"SAS(0x5000c50012345678,0x0,0x1,SATA,Internal,Direct,0x3,0x0)",
+			"030a2c00 b4dd87d48b00d911afdc001083ffca4d 00000000 7856341200c50050 0000000000000000 1202 0100",
+		},
+		{
+			"sas no topology",
+			&SASDevicePath{SASAddress: 1},
+			"SAS(0x1,0x0,0x0,NoTopology,0,0,0,0x0)",
+			"030a2c00 b4dd87d48b00d911afdc001083ffca4d 00000000 0100000000000000 0000000000000000 0000 0000",
+		},
+		{
+			"sas ex",
+			&SASExDevicePath{
+				SASAddress:         [8]byte{0x50, 0x00, 0xc5, 0x00, 0x12, 0x34, 0x56, 0x78},
+				DeviceTopology:     0x0061,
+				RelativeTargetPort: 2,
+			},
+			"SasEx(0x5000c50012345678,0x0000000000000000,0x2,SAS,External,Expanded,0)",
+			"03161800 5000c50012345678 0000000000000000 6100 0200",
+		},
+		{
+			"unit",
+			&DeviceLogicalUnitDevicePath{LUN: 3},
+			"Unit(0x3)",
+			"03110500 03",
+		},
+		{
+			"sata",
+			&SATADevicePath{HBAPortNumber: 0, PortMultiplierPortNumber: 0xffff, LUN: 0},
+			"Sata(0x0,0xffff,0x0)",
+			"03120a00 0000 ffff 0000",
+		},
+		{
+			"nvme",
+			&NVMeNamespaceDevicePath{NamespaceID: 1, EUI64: 0x0025385b71b0a1c2},
+			"NVMe(0x1,00-25-38-5b-71-b0-a1-c2)",
+			"03171000 01000000 c2a1b0715b382500",
+		},
+		{
+			"ufs",
+			&UFSDevicePath{PUN: 0, LUN: 1},
+			"UFS(0x0,0x1)",
+			"03190600 00 01",
+		},
+		{
+			"sd",
+			&SDDevicePath{SlotNumber: 1},
+			"SD(0x1)",
+			"031a0500 01",
+		},
+		{
+			"emmc",
+			&EMMCDevicePath{SlotNumber: 0},
+			"eMMC(0x0)",
+			"031d0500 00",
+		},
+	})
+}
+
+func TestParseText_Messaging(t *testing.T) {
+	p, err := ParseText("Ata(1,Slave,0)/Sata(1,65535,0)")
+	if err != nil {
+		t.Fatalf("ParseText() error = %v", err)
+	}
+
+	want := []string{"Ata(Secondary,Slave,0x0)/Sata(0x1,0xffff,0x0)"}
+	if got := p.AllText(); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("AllText() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,945 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
+)
+
+const (
+	tcpProtocol = 6
+	udpProtocol = 17
+
+	// ipv4LegacyLength is the length of IPv4 Device Path nodes
+	// defined before the gateway and subnet mask fields were added.
+	ipv4LegacyLength = 19
+
+	// ipv6LegacyLength is the length of IPv6 Device Path nodes
+	// defined before the prefix length and gateway fields were
+	// added.
+	ipv6LegacyLength = 43
+)
+
+func protocolText(p uint16) string {
+	switch p {
+	case tcpProtocol:
+		return "TCP"
+	case udpProtocol:
+		return "UDP"
+	default:
+		return fmt.Sprintf("%#x", p)
+	}
+}
+
+func parseProtocolText(a *textArgs, i int) uint16 {
+	switch a.str(i) {
+	case "TCP":
+		return tcpProtocol
+	case "UDP":
+		return udpProtocol
+	default:
+		return a.u16(i)
+	}
+}
+
+func ipv4Text(ip [4]byte) string {
+	return net.IP(ip[:]).String()
+}
+
+func ipv6Text(ip [16]byte) string {
+	parts := make([]string, 8)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("%02x%02x", ip[i*2], ip[i*2+1])
+	}
+	return strings.Join(parts, ":")
+}
+
+// ipv4 parses the argument at the given position as an IPv4
+// address. A missing argument is treated as the zero address.
+func (a *textArgs) ipv4(i int) (out [4]byte) {
+	s := a.str(i)
+	if s == "" {
+		return
+	}
+
+	ip := net.ParseIP(s).To4()
+	if ip == nil {
+		a.fail(i, ErrInvalidText)
+		return
+	}
+	copy(out[:], ip)
+	return
+}
+
+// ipv6 parses the argument at the given position as an IPv6
+// address. A missing argument is treated as the zero address.
+func (a *textArgs) ipv6(i int) (out [16]byte) {
+	s := a.str(i)
+	if s == "" {
+		return
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() != nil {
+		a.fail(i, ErrInvalidText)
+		return
+	}
+	copy(out[:], ip)
+	return
+}
+
+// MACAddressDevicePath defines the MAC address of a network
+// interface.
+//
+// Section 10.3.4.12 "MAC Address Device Path"
+type MACAddressDevicePath struct {
+	Head
+
+	// MACAddress is the network interface MAC address padded
+	// with zeroes.
+	MACAddress [32]byte
+
+	// IfType is the network interface type as defined by
+	// RFC 3232.  0 and 1 denote Ethernet.
+	IfType uint8
+}
+
+// HardwareAddr returns the significant part of the MACAddress field.
+func (p *MACAddressDevicePath) HardwareAddr() net.HardwareAddr {
+	if p.IfType == 0 || p.IfType == 1 {
+		return p.MACAddress[:6]
+	}
+	return p.MACAddress[:]
+}
+
+func (p *MACAddressDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *MACAddressDevicePath) Text() string {
+	return fmt.Sprintf("MAC(%x,%#x)", []byte(p.HardwareAddr()), p.IfType)
+}
+
+func (p *MACAddressDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.MACAddress, &p.IfType)
+}
+
+func (p *MACAddressDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, MACAddressSubType, p.MACAddress, p.IfType)
+}
+
+// parseMACAddressText parses MAC(Address,IfType).
+func parseMACAddressText(a *textArgs) DevicePath {
+	p := &MACAddressDevicePath{IfType: a.u8(1)}
+	if addr := a.hex(0); len(addr) > len(p.MACAddress) {
+		a.fail(0, ErrInvalidText)
+	} else {
+		copy(p.MACAddress[:], addr)
+	}
+	return p
+}
+
+// IPv4DevicePath defines an IPv4 network connection.
+//
+// Section 10.3.4.13 "IPv4 Device Path"
+type IPv4DevicePath struct {
+	Head
+
+	// LocalIPAddress is the local IPv4 address.
+	LocalIPAddress [4]byte
+
+	// RemoteIPAddress is the remote IPv4 address.
+	RemoteIPAddress [4]byte
+
+	// LocalPort is the local port number.
+	LocalPort uint16
+
+	// RemotePort is the remote port number.
+	RemotePort uint16
+
+	// Protocol is the network protocol as defined by RFC 1700.
+	Protocol uint16
+
+	// StaticIPAddress is true if the local address was assigned
+	// statically and false if it was assigned through DHCP.
+	StaticIPAddress bool
+
+	// GatewayIPAddress is the gateway IPv4 address.
+	GatewayIPAddress [4]byte
+
+	// SubnetMask is the subnet mask.
+	SubnetMask [4]byte
+}
+
+func (p *IPv4DevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *IPv4DevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *IPv4DevicePath) FormatText(flags TextFlags) string {
+	if flags&DisplayOnly != 0 {
+		return fmt.Sprintf("IPv4(%s)", ipv4Text(p.RemoteIPAddress))
+	}
+
+	addressing := "DHCP"
+	if p.StaticIPAddress {
+		addressing = "Static"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"IPv4(%s,%s,%s,%s",
+		ipv4Text(p.RemoteIPAddress),
+		protocolText(p.Protocol),
+		addressing,
+		ipv4Text(p.LocalIPAddress),
+	)
+	if p.Length != ipv4LegacyLength {
+		fmt.Fprintf(&b, ",%s,%s", ipv4Text(p.GatewayIPAddress), ipv4Text(p.SubnetMask))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (p *IPv4DevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	err = fr.ReadFields(
+		&p.LocalIPAddress,
+		&p.RemoteIPAddress,
+		&p.LocalPort,
+		&p.RemotePort,
+		&p.Protocol,
+		&p.StaticIPAddress,
+	)
+	if err != nil {
+		return
+	}
+
+	// Nodes defined by older revisions of the specification end
+	// at this point.
+	if err = fr.ReadFields(&p.GatewayIPAddress, &p.SubnetMask); errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return
+}
+
+func (p *IPv4DevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	fields := []any{
+		p.LocalIPAddress,
+		p.RemoteIPAddress,
+		p.LocalPort,
+		p.RemotePort,
+		p.Protocol,
+		p.StaticIPAddress,
+	}
+	if p.Length != ipv4LegacyLength {
+		fields = append(fields, p.GatewayIPAddress, p.SubnetMask)
+	}
+	return writeNode(w, MessagingType, IPv4SubType, fields...)
+}
+
+// parseIPv4Text parses
+// IPv4(RemoteIp,Protocol,Type,LocalIp,GatewayIp,SubnetMask).
+func parseIPv4Text(a *textArgs) DevicePath {
+	return &IPv4DevicePath{
+		RemoteIPAddress:  a.ipv4(0),
+		Protocol:         parseProtocolText(a, 1),
+		StaticIPAddress:  a.enum(2, 8, "DHCP", "Static") != 0,
+		LocalIPAddress:   a.ipv4(3),
+		GatewayIPAddress: a.ipv4(4),
+		SubnetMask:       a.ipv4(5),
+	}
+}
+
+// IPv6DevicePath defines an IPv6 network connection.
+//
+// Section 10.3.4.14 "IPv6 Device Path"
+type IPv6DevicePath struct {
+	Head
+
+	// LocalIPAddress is the local IPv6 address.
+	LocalIPAddress [16]byte
+
+	// RemoteIPAddress is the remote IPv6 address.
+	RemoteIPAddress [16]byte
+
+	// LocalPort is the local port number.
+	LocalPort uint16
+
+	// RemotePort is the remote port number.
+	RemotePort uint16
+
+	// Protocol is the network protocol as defined by RFC 1700.
+	Protocol uint16
+
+	// IPAddressOrigin describes how the local address was
+	// assigned: 0 statically, 1 by stateless auto-configuration
+	// and 2 by stateful auto-configuration.
+	IPAddressOrigin uint8
+
+	// PrefixLength is the length of the address prefix.
+	PrefixLength uint8
+
+	// GatewayIPAddress is the gateway IPv6 address.
+	GatewayIPAddress [16]byte
+}
+
+var ipv6OriginNames = []string{"Static", "StatelessAutoConfigure", "StatefulAutoConfigure"}
+
+func (p *IPv6DevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *IPv6DevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *IPv6DevicePath) FormatText(flags TextFlags) string {
+	if flags&DisplayOnly != 0 {
+		return fmt.Sprintf("IPv6(%s)", ipv6Text(p.RemoteIPAddress))
+	}
+
+	origin := ipv6OriginNames[2]
+	if int(p.IPAddressOrigin) < len(ipv6OriginNames) {
+		origin = ipv6OriginNames[p.IPAddressOrigin]
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(
+		&b,
+		"IPv6(%s,%s,%s,%s",
+		ipv6Text(p.RemoteIPAddress),
+		protocolText(p.Protocol),
+		origin,
+		ipv6Text(p.LocalIPAddress),
+	)
+	if p.Length != ipv6LegacyLength {
+		fmt.Fprintf(&b, ",%#x,%s", p.PrefixLength, ipv6Text(p.GatewayIPAddress))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+func (p *IPv6DevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	err = fr.ReadFields(
+		&p.LocalIPAddress,
+		&p.RemoteIPAddress,
+		&p.LocalPort,
+		&p.RemotePort,
+		&p.Protocol,
+		&p.IPAddressOrigin,
+	)
+	if err != nil {
+		return
+	}
+
+	// Nodes defined by older revisions of the specification end
+	// at this point.
+	if err = fr.ReadFields(&p.PrefixLength, &p.GatewayIPAddress); errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return
+}
+
+func (p *IPv6DevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	fields := []any{
+		p.LocalIPAddress,
+		p.RemoteIPAddress,
+		p.LocalPort,
+		p.RemotePort,
+		p.Protocol,
+		p.IPAddressOrigin,
+	}
+	if p.Length != ipv6LegacyLength {
+		fields = append(fields, p.PrefixLength, p.GatewayIPAddress)
+	}
+	return writeNode(w, MessagingType, IPv6SubType, fields...)
+}
+
+// parseIPv6Text parses
+// IPv6(RemoteIp,Protocol,IPAddressOrigin,LocalIp,PrefixLength,GatewayIp).
+func parseIPv6Text(a *textArgs) DevicePath {
+	return &IPv6DevicePath{
+		RemoteIPAddress:  a.ipv6(0),
+		Protocol:         parseProtocolText(a, 1),
+		IPAddressOrigin:  uint8(a.enum(2, 8, ipv6OriginNames...)),
+		LocalIPAddress:   a.ipv6(3),
+		PrefixLength:     a.u8(4),
+		GatewayIPAddress: a.ipv6(5),
+	}
+}
+
+// VLANDevicePath defines a VLAN of a network interface.
+//
+// Section 10.3.4.15 "VLAN device path node"
+type VLANDevicePath struct {
+	Head
+
+	// VLANID is the VLAN identifier (0-4094).
+	VLANID uint16
+}
+
+func (p *VLANDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *VLANDevicePath) Text() string {
+	return fmt.Sprintf("Vlan(%d)", p.VLANID)
+}
+
+func (p *VLANDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.VLANID)
+}
+
+func (p *VLANDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, VLANSubType, p.VLANID)
+}
+
+// parseVLANText parses Vlan(VlanId).
+func parseVLANText(a *textArgs) DevicePath {
+	return &VLANDevicePath{VLANID: a.u16(0)}
+}
+
+// InfiniBandDevicePath defines the path to an InfiniBand device.
+//
+// Section 10.3.4.16 "InfiniBand Device Path"
+type InfiniBandDevicePath struct {
+	Head
+
+	// ResourceFlags describes the kind of the InfiniBand
+	// resource.
+	ResourceFlags uint32
+
+	// PortGID is the 128-bit Global Identifier of the remote
+	// port.
+	PortGID efiguid.GUID
+
+	// ServiceID is either the 64-bit unique identifier of the
+	// remote IOC or the service ID, depending on ResourceFlags.
+	ServiceID uint64
+
+	// TargetPortID is the 64-bit persistent ID of the remote
+	// IOC port.
+	TargetPortID uint64
+
+	// DeviceID is the 64-bit persistent ID of the remote device.
+	DeviceID uint64
+}
+
+func (p *InfiniBandDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *InfiniBandDevicePath) Text() string {
+	return fmt.Sprintf(
+		"Infiniband(%#x,%s,%#x,%#x,%#x)",
+		p.ResourceFlags,
+		p.PortGID,
+		p.ServiceID,
+		p.TargetPortID,
+		p.DeviceID,
+	)
+}
+
+func (p *InfiniBandDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.ResourceFlags, &p.PortGID, &p.ServiceID, &p.TargetPortID, &p.DeviceID)
+}
+
+func (p *InfiniBandDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		MessagingType,
+		InfiniBandSubType,
+		p.ResourceFlags,
+		p.PortGID,
+		p.ServiceID,
+		p.TargetPortID,
+		p.DeviceID,
+	)
+}
+
+// parseInfiniBandText parses
+// Infiniband(Flags,Guid,ServiceId,TargetId,DeviceId).
+func parseInfiniBandText(a *textArgs) DevicePath {
+	return &InfiniBandDevicePath{
+		ResourceFlags: a.u32(0),
+		PortGID:       a.guid(1),
+		ServiceID:     a.u64(2),
+		TargetPortID:  a.u64(3),
+		DeviceID:      a.u64(4),
+	}
+}
+
+const (
+	iSCSIHeaderDigestCRC32C = 0x0002
+	iSCSIDataDigestCRC32C   = 0x0008
+	iSCSIAuthNone           = 0x0800
+	iSCSIAuthCHAPUni        = 0x1000
+)
+
+// ISCSIDevicePath defines an iSCSI target.
+//
+// Section 10.3.4.22 "iSCSI Device Path"
+type ISCSIDevicePath struct {
+	Head
+
+	// NetworkProtocol is 0 for TCP, all other values are
+	// reserved.
+	NetworkProtocol uint16
+
+	// LoginOption describes the digest and authentication
+	// settings used for the iSCSI login.
+	LoginOption uint16
+
+	// LUN is the 8 byte Logical Unit Number.
+	LUN [8]byte
+
+	// TargetPortalGroupTag is the iSCSI target portal group tag.
+	TargetPortalGroupTag uint16
+
+	// TargetName is the iSCSI node name in ASCII.
+	TargetName []byte `efijson:"ascii"`
+}
+
+func (p *ISCSIDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *ISCSIDevicePath) Text() string {
+	headerDigest, dataDigest, auth, protocol := "None", "None", "CHAP_BI", "TCP"
+	if p.LoginOption&iSCSIHeaderDigestCRC32C != 0 {
+		headerDigest = "CRC32C"
+	}
+	if p.LoginOption&iSCSIDataDigestCRC32C != 0 {
+		dataDigest = "CRC32C"
+	}
+	switch {
+	case p.LoginOption&iSCSIAuthNone != 0:
+		auth = "None"
+	case p.LoginOption&iSCSIAuthCHAPUni != 0:
+		auth = "CHAP_UNI"
+	}
+	if p.NetworkProtocol != 0 {
+		protocol = "reserved"
+	}
+
+	return fmt.Sprintf(
+		"iSCSI(%s,%#x,%#x,%s,%s,%s,%s)",
+		efireader.ASCIIZBytesToString(p.TargetName),
+		p.TargetPortalGroupTag,
+		p.LUN[:],
+		headerDigest,
+		dataDigest,
+		auth,
+		protocol,
+	)
+}
+
+func (p *ISCSIDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	err = fr.ReadFields(&p.NetworkProtocol, &p.LoginOption, &p.LUN, &p.TargetPortalGroupTag)
+	if err != nil {
+		return
+	}
+
+	p.TargetName, err = io.ReadAll(fr)
+	return
+}
+
+func (p *ISCSIDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		MessagingType,
+		ISCSISubType,
+		p.NetworkProtocol,
+		p.LoginOption,
+		p.LUN,
+		p.TargetPortalGroupTag,
+		p.TargetName,
+	)
+}
+
+// parseISCSIText parses iSCSI(TargetName,PortalGroup,LUN,
+// HeaderDigest,DataDigest,Authentication,Protocol).
+func parseISCSIText(a *textArgs) DevicePath {
+	p := &ISCSIDevicePath{
+		TargetName:           efiwriter.TerminateASCII([]byte(a.str(0))),
+		TargetPortalGroupTag: a.u16(1),
+		LUN:                  a.be8(2),
+	}
+
+	if a.str(3) == "CRC32C" {
+		p.LoginOption |= iSCSIHeaderDigestCRC32C
+	}
+	if a.str(4) == "CRC32C" {
+		p.LoginOption |= iSCSIDataDigestCRC32C
+	}
+	switch a.str(5) {
+	case "None":
+		p.LoginOption |= iSCSIAuthNone
+	case "CHAP_UNI":
+		p.LoginOption |= iSCSIAuthCHAPUni
+	}
+	if protocol := a.str(6); protocol != "" && protocol != "TCP" {
+		p.NetworkProtocol = 1
+	}
+	return p
+}
+
+// URIDevicePath defines a Uniform Resource Identifier.
+//
+// Section 10.3.4.24 "Uniform Resource Identifiers (URI) Device Path"
+type URIDevicePath struct {
+	Head
+
+	// URI is the URI as defined by RFC 3986.
+	URI []byte `efijson:"ascii"`
+}
+
+func (p *URIDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *URIDevicePath) Text() string {
+	return fmt.Sprintf("Uri(%s)", p.URI)
+}
+
+func (p *URIDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	p.URI, err = io.ReadAll(r)
+	n = int64(len(p.URI))
+	return
+}
+
+func (p *URIDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, URISubType, p.URI)
+}
+
+// parseURIText parses Uri(Uri).  Commas are part of valid URIs and
+// therefore the whole parameter list is taken.
+func parseURIText(a *textArgs) DevicePath {
+	return &URIDevicePath{URI: []byte(a.all())}
+}
+
+// DNSDevicePath defines the DNS servers of a network connection.
+//
+// Section 10.3.4.31 "DNS Device Path"
+type DNSDevicePath struct {
+	Head
+
+	// IsIPv6 is true if the server addresses are IPv6 addresses.
+	IsIPv6 bool
+
+	// DNSServerIPs are the server addresses. IPv4 addresses
+	// occupy the first four bytes of each entry.
+	DNSServerIPs [][16]byte
+}
+
+func (p *DNSDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *DNSDevicePath) Text() string {
+	servers := make([]string, len(p.DNSServerIPs))
+	for i, ip := range p.DNSServerIPs {
+		if p.IsIPv6 {
+			servers[i] = ipv6Text(ip)
+		} else {
+			servers[i] = ipv4Text([4]byte{ip[0], ip[1], ip[2], ip[3]})
+		}
+	}
+	return fmt.Sprintf("Dns(%s)", strings.Join(servers, ","))
+}
+
+func (p *DNSDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	if err = fr.ReadFields(&p.IsIPv6); err != nil {
+		return
+	}
+
+	p.DNSServerIPs = nil
+	for {
+		var ip [16]byte
+		if err = fr.ReadFields(&ip); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+			return
+		}
+		p.DNSServerIPs = append(p.DNSServerIPs, ip)
+	}
+}
+
+func (p *DNSDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, DNSSubType, p.IsIPv6, p.DNSServerIPs)
+}
+
+// parseDNSText parses Dns(DnsServerIp,...).  The address family is
+// derived from the first address.
+func parseDNSText(a *textArgs) DevicePath {
+	p := &DNSDevicePath{IsIPv6: strings.Contains(a.str(0), ":")}
+	for i := range a.args {
+		var ip [16]byte
+		if p.IsIPv6 {
+			ip = a.ipv6(i)
+		} else {
+			v4 := a.ipv4(i)
+			copy(ip[:], v4[:])
+		}
+		p.DNSServerIPs = append(p.DNSServerIPs, ip)
+	}
+	return p
+}
+
+// WiFiDevicePath defines a Wi-Fi network.
+//
+// Section 10.3.4.28 "Wireless Device Path"
+type WiFiDevicePath struct {
+	Head
+
+	// SSID is the network name padded with zeroes.
+	SSID [32]byte
+}
+
+func (p *WiFiDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *WiFiDevicePath) Text() string {
+	return fmt.Sprintf("Wi-Fi(%s)", efireader.ASCIIZBytesToString(p.SSID[:]))
+}
+
+func (p *WiFiDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.SSID)
+}
+
+func (p *WiFiDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, WiFiSubType, p.SSID)
+}
+
+// parseWiFiText parses Wi-Fi(SSID).
+func parseWiFiText(a *textArgs) DevicePath {
+	p := &WiFiDevicePath{}
+	if ssid := a.str(0); len(ssid) > len(p.SSID) {
+		a.fail(0, ErrInvalidText)
+	} else {
+		copy(p.SSID[:], ssid)
+	}
+	return p
+}
+
+// BluetoothDevicePath defines the path to a Bluetooth device.
+//
+// Section 10.3.4.27 "EFI Bluetooth Device Path"
+type BluetoothDevicePath struct {
+	Head
+
+	// Address is the 48-bit Bluetooth device address.
+	Address [6]byte
+}
+
+func (p *BluetoothDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *BluetoothDevicePath) Text() string {
+	return fmt.Sprintf("Bluetooth(%x)", p.Address[:])
+}
+
+func (p *BluetoothDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Address)
+}
+
+func (p *BluetoothDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, BluetoothSubType, p.Address)
+}
+
+// parseBluetoothText parses Bluetooth(Address).
+func parseBluetoothText(a *textArgs) DevicePath {
+	p := &BluetoothDevicePath{}
+	if addr := a.hex(0); len(addr) != len(p.Address) {
+		a.fail(0, ErrInvalidText)
+	} else {
+		copy(p.Address[:], addr)
+	}
+	return p
+}
+
+// BluetoothLEDevicePath defines the path to a Bluetooth Low Energy
+// device.
+//
+// Section 10.3.4.30 "EFI BluetoothLE Device Path"
+type BluetoothLEDevicePath struct {
+	Head
+
+	// Address is the 48-bit Bluetooth device address.
+	Address [6]byte
+
+	// AddressType is 0 for a public and 1 for a random device
+	// address.
+	AddressType uint8
+}
+
+func (p *BluetoothLEDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *BluetoothLEDevicePath) Text() string {
+	return fmt.Sprintf("BluetoothLE(%x,%#x)", p.Address[:], p.AddressType)
+}
+
+func (p *BluetoothLEDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Address, &p.AddressType)
+}
+
+func (p *BluetoothLEDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, BluetoothLESubType, p.Address, p.AddressType)
+}
+
+// parseBluetoothLEText parses BluetoothLE(Address,Type).
+func parseBluetoothLEText(a *textArgs) DevicePath {
+	p := &BluetoothLEDevicePath{AddressType: a.u8(1)}
+	if addr := a.hex(0); len(addr) != len(p.Address) {
+		a.fail(0, ErrInvalidText)
+	} else {
+		copy(p.Address[:], addr)
+	}
+	return p
+}
+
+const (
+	// RedfishRESTService denotes a Redfish REST service.
+	RedfishRESTService uint8 = 0x01
+
+	// ODataRESTService denotes an OData REST service.
+	ODataRESTService uint8 = 0x02
+
+	// VendorRESTService denotes a vendor-specific REST service
+	// identified by the VendorGUID field.
+	VendorRESTService uint8 = 0xff
+)
+
+// RESTServiceDevicePath defines a REST service.
+//
+// Section 10.3.4.33 "REST Service Device Path"
+type RESTServiceDevicePath struct {
+	Head
+
+	// RESTService is the kind of the REST service.
+	RESTService uint8
+
+	// AccessMode is 1 for in-band and 2 for out-of-band access.
+	AccessMode uint8
+
+	// VendorGUID identifies the vendor-specific REST service and
+	// is only present if RESTService is VendorRESTService.
+	VendorGUID efiguid.GUID
+
+	// VendorDefinedData is the vendor-specific data and is only
+	// present if RESTService is VendorRESTService.
+	VendorDefinedData []byte
+}
+
+func (p *RESTServiceDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *RESTServiceDevicePath) Text() string {
+	switch p.RESTService {
+	case RedfishRESTService:
+		return fmt.Sprintf("RestService(RedFish,%#x)", p.AccessMode)
+	case ODataRESTService:
+		return fmt.Sprintf("RestService(OData,%#x)", p.AccessMode)
+	case VendorRESTService:
+		return fmt.Sprintf(
+			"RestService(VendorSpecific,%#x,%s,%x)",
+			p.AccessMode,
+			p.VendorGUID,
+			p.VendorDefinedData,
+		)
+	default:
+		return fmt.Sprintf("RestService(%#x,%#x)", p.RESTService, p.AccessMode)
+	}
+}
+
+func (p *RESTServiceDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	if err = fr.ReadFields(&p.RESTService, &p.AccessMode); err != nil {
+		return
+	}
+
+	if p.RESTService == VendorRESTService {
+		if err = fr.ReadFields(&p.VendorGUID); err != nil {
+			return
+		}
+		p.VendorDefinedData, err = io.ReadAll(fr)
+	}
+	return
+}
+
+func (p *RESTServiceDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	if p.RESTService == VendorRESTService {
+		return writeNode(
+			w,
+			MessagingType,
+			RESTServiceSubType,
+			p.RESTService,
+			p.AccessMode,
+			p.VendorGUID,
+			p.VendorDefinedData,
+		)
+	}
+	return writeNode(w, MessagingType, RESTServiceSubType, p.RESTService, p.AccessMode)
+}
+
+// parseRESTServiceText parses RestService(RESTService,AccessMode)
+// and RestService(VendorSpecific,AccessMode,GUID,Data).
+func parseRESTServiceText(a *textArgs) DevicePath {
+	p := &RESTServiceDevicePath{AccessMode: a.u8(1)}
+
+	switch a.str(0) {
+	case "RedFish":
+		p.RESTService = RedfishRESTService
+	case "OData":
+		p.RESTService = ODataRESTService
+	case "VendorSpecific":
+		p.RESTService = VendorRESTService
+	default:
+		p.RESTService = a.u8(0)
+	}
+
+	if p.RESTService == VendorRESTService {
+		p.VendorGUID = a.guid(2)
+		p.VendorDefinedData = a.hex(3)
+	}
+	return p
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+func TestMessagingDevicePath_Network(t *testing.T) {
+	vendorGUID := efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7")
+
+	runNodeTests(t, []nodeTestCase{
+		{
+			"mac",
+			&MACAddressDevicePath{MACAddress: [32]byte{0x52, 0x54, 0x00, 0x12, 0x34, 0x56}, IfType: 1},
+			"MAC(525400123456,0x1)",
+			"030b2500 525400123456 0000000000000000000000000000000000000000000000000000 01",
+		},
+		{
+			"ipv4",
+			&IPv4DevicePath{
+				LocalIPAddress:   [4]byte{192, 168, 0, 2},
+				RemoteIPAddress:  [4]byte{192, 168, 0, 1},
+				Protocol:         17,
+				GatewayIPAddress: [4]byte{192, 168, 0, 1},
+				SubnetMask:       [4]byte{255, 255, 255, 0},
+			},
+			"IPv4(192.168.0.1,UDP,DHCP,192.168.0.2,192.168.0.1,255.255.255.0)",
+			"030c1b00 c0a80002 c0a80001 0000 0000 1100 00 c0a80001 ffffff00",
+		},
+		{
+			"ipv6",
+			&IPv6DevicePath{
+				LocalIPAddress:  [16]byte{0xfe, 0x80, 15: 0x01},
+				RemoteIPAddress: [16]byte{0x20, 0x01, 0x0d, 0xb8, 15: 0x01},
+				Protocol:        6,
+				IPAddressOrigin: 1,
+				PrefixLength:    64,
+			},
+			"IPv6(2001:0db8:0000:0000:0000:0000:0000:0001,TCP,StatelessAutoConfigure," +
+				"fe80:0000:0000:0000:0000:0000:0000:0001,0x40,0000:0000:0000:0000:0000:0000:0000:0000)",
+			"030d3c00 fe800000000000000000000000000001 20010db8000000000000000000000001" +
+				" 0000 0000 0600 01 40 00000000000000000000000000000000",
+		},
+		{
+			"vlan",
+			&VLANDevicePath{VLANID: 100},
+			"Vlan(100)",
+			"03140600 6400",
+		},
+		{
+			"infiniband",
+			&InfiniBandDevicePath{ResourceFlags: 1, PortGID: vendorGUID, ServiceID: 2, TargetPortID: 3, DeviceID: 4},
+			"Infiniband(0x1,3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,0x2,0x3,0x4)",
+			"03093000 01000000 3f9fd93c2b4beb43ac29f0890a4772b7" +
+				" 0200000000000000 0300000000000000 0400000000000000",
+		},
+		{
+			"iscsi",
+			&ISCSIDevicePath{
+				LoginOption:          iSCSIHeaderDigestCRC32C | iSCSIAuthNone,
+				LUN:                  [8]byte{7: 1},
+				TargetPortalGroupTag: 1,
+				TargetName:           []byte("iqn.2004-01.org:target\x00"),
+			},
+			"iSCSI(iqn.2004-01.org:target,0x1,0x0000000000000001,CRC32C,None,None,TCP)",
+			"03132900 0000 0208 0000000000000001 0100 69716e2e323030342d30312e6f72673a74617267657400",
+		},
+		{
+			"uri",
+			&URIDevicePath{URI: []byte("http://192.168.0.1/boot.efi")},
+			"Uri(http://192.168.0.1/boot.efi)",
+			"03181f00 687474703a2f2f3139322e3136382e302e312f626f6f742e656669",
+		},
+		{
+			"dns",
+			&DNSDevicePath{DNSServerIPs: [][16]byte{{8, 8, 8, 8}, {1, 1, 1, 1}}},
+			"Dns(8.8.8.8,1.1.1.1)",
+			"031f2500 00 08080808000000000000000000000000 01010101000000000000000000000000",
+		},
+		{
+			"dns ipv6",
+			&DNSDevicePath{IsIPv6: true, DNSServerIPs: [][16]byte{{0x20, 0x01, 15: 0x01}}},
+			"Dns(2001:0000:0000:0000:0000:0000:0000:0001)",
+			"031f1500 01 20010000000000000000000000000001",
+		},
+		{
+			"wifi",
+			&WiFiDevicePath{SSID: [32]byte{'h', 'o', 'm', 'e'}},
+			"Wi-Fi(home)",
+			"031c2400 686f6d65 00000000000000000000000000000000000000000000000000000000",
+		},
+		{
+			"bluetooth",
+			&BluetoothDevicePath{Address: [6]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}},
+			"Bluetooth(001122334455)",
+			"031b0a00 001122334455",
+		},
+		{
+			"bluetooth le",
+			&BluetoothLEDevicePath{Address: [6]byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}, AddressType: 1},
+			"BluetoothLE(001122334455,0x1)",
+			"031e0b00 001122334455 01",
+		},
+		{
+			"rest service",
+			&RESTServiceDevicePath{RESTService: RedfishRESTService, AccessMode: 2},
+			"RestService(RedFish,0x2)",
+			"03210600 01 02",
+		},
+		{
+			"vendor rest service",
+			&RESTServiceDevicePath{
+				RESTService:       VendorRESTService,
+				AccessMode:        1,
+				VendorGUID:        vendorGUID,
+				VendorDefinedData: []byte{0xaa},
+			},
+			"RestService(VendorSpecific,0x1,3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,aa)",
+			"03211700 ff 01 3f9fd93c2b4beb43ac29f0890a4772b7 aa",
+		},
+	})
+}
+
+func TestMessagingDevicePath_NetworkLegacy(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+		want string
+	}{
+		{
+			"ipv4",
+			"030c1300 00000000 0a000001 0000 0000 0600 01",
+			"IPv4(10.0.0.1,TCP,Static,0.0.0.0)",
+		},
+		{
+			"ipv6",
+			"030d2b00 00000000000000000000000000000000 20010db8000000000000000000000001 0000 0000 0600 00",
+			"IPv6(2001:0db8:0000:0000:0000:0000:0000:0001,TCP,Static,0000:0000:0000:0000:0000:0000:0000:0000)",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inp := mustDecodeHex(t, tc.inp+"7fff0400")
+
+			var p DevicePaths
+			if _, err := p.ReadFrom(bytes.NewReader(inp)); err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if got := p[0].Text(); got != tc.want {
+				t.Errorf("Text() = %v, want %v", got, tc.want)
+			}
+
+			var buf bytes.Buffer
+			if _, err := p.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), inp) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), inp)
+			}
+		})
+	}
+}
+
+func TestParseText_Network(t *testing.T) {
+	p, err := ParseText("Pci(0,25)/MAC(525400123456,1)/IPv4(10.0.0.1)/Uri(http://example.com/a,b)")
+	if err != nil {
+		t.Fatalf("ParseText() error = %v", err)
+	}
+
+	want := "Pci(0x0,0x19)/MAC(525400123456,0x1)/IPv4(10.0.0.1,0x0,DHCP,0.0.0.0,0.0.0.0,0.0.0.0)/Uri(http://example.com/a,b)"
+	if got := strings.Join(p.AllText(), ","); got != want {
+		t.Errorf("AllText() = %v, want %v", got, want)
+	}
+}
@@ -17,8 +17,7 @@ package efidevicepath
 import (
 	"fmt"
 	"io"
-
-	"github.com/0x5a17ed/uefi/efi/efihex"
+	"strings"
 )
 
 // EndOfPath terminates a Device Path.
@@ -28,6 +27,32 @@ func (p *EndOfPath) ReadFrom(r io.Reader) (n int64, err error) { return 0, nil }
 func (p *EndOfPath) GetHead() *Head                            { return &p.Head }
 func (p *EndOfPath) Text() string                              { return "" }
 
+// WriteTo writes the End of Device Path node to w.  A node without a
+// SubType terminates the entire Device Path.
+func (p *EndOfPath) WriteTo(w io.Writer) (n int64, err error) {
+	st := p.SubType
+	if st == 0 {
+		st = EndEntireSubType
+	}
+	return writeNode(w, EndOfPathType, st)
+}
+
+// endSubType reports whether d is an End of Device Path node and
+// returns the subtype it is encoded with, which is EndEntireSubType
+// for an EndOfPath node without a SubType.
+func endSubType(d DevicePath) (DevicePathSubType, bool) {
+	if p, ok := d.(*EndOfPath); ok {
+		if p.SubType == 0 {
+			return EndEntireSubType, true
+		}
+		return p.SubType, true
+	}
+	if h := d.GetHead(); h.Type == EndOfPathType {
+		return h.SubType, true
+	}
+	return 0, false
+}
+
 const (
 	_ DevicePathSubType = iota
 
@@ -52,17 +77,36 @@ func (p *UnrecognizedDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
 	return
 }
 
+func (p *UnrecognizedDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, p.Type, p.SubType, p.Data)
+}
+
 func (p *UnrecognizedDevicePath) GetHead() *Head {
 	return &p.Head
 }
 
+// genericPathNames maps the Device Path types to the names of the
+// generic text representation of their nodes.
+var genericPathNames = map[DevicePathType]string{
+	HardwareType:  "HardwarePath",
+	ACPIType:      "AcpiPath",
+	MessagingType: "Msg",
+	MediaType:     "MediaPath",
+	BIOSBootType:  "BbsPath",
+}
+
 func (p *UnrecognizedDevicePath) Text() string {
-	return fmt.Sprintf(
-		"Path(%d,%d,%s)",
-		p.Head.Type,
-		p.Head.SubType,
-		efihex.EncodeToString(p.Data),
-	)
+	var b strings.Builder
+	if name, ok := genericPathNames[p.Type]; ok {
+		fmt.Fprintf(&b, "%s(%d", name, p.SubType)
+	} else {
+		fmt.Fprintf(&b, "Path(%d,%d", p.Type, p.SubType)
+	}
+	if len(p.Data) > 0 {
+		fmt.Fprintf(&b, ",%x", p.Data)
+	}
+	b.WriteString(")")
+	return b.String()
 }
 
 func ParseUnrecognizedDevicePath(r io.Reader, h Head) (p DevicePath, err error) {
@@ -1,41 +0,0 @@
-// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at
-//
-// <https://www.apache.org/licenses/LICENSE-2.0>
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
-
-package efidevicepath
-
-import (
-	"testing"
-)
-
-func Test_formatString(t *testing.T) {
-	type args struct {
-		s string
-	}
-	tt := []struct {
-		name string
-		args args
-		want string
-	}{
-		{"empty", args{""}, `""`},
-		{"simple", args{"foo"}, `foo`},
-	}
-	for _, tc := range tt {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			if got := formatString(tc.args.s); got != tc.want {
-				t.Errorf("formatString() = %v, want %v", got, tc.want)
-			}
-		})
-	}
-}
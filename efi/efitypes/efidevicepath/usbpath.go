@@ -0,0 +1,304 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+// USBDevicePath defines the path to a USB device by its port.
+//
+// Section 10.3.4.5 "USB Device Path"
+type USBDevicePath struct {
+	Head
+
+	// ParentPortNumber is the USB parent port number.
+	ParentPortNumber uint8
+
+	// InterfaceNumber is the USB interface number.
+	InterfaceNumber uint8
+}
+
+func (p *USBDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *USBDevicePath) Text() string {
+	return fmt.Sprintf("USB(%#x,%#x)", p.ParentPortNumber, p.InterfaceNumber)
+}
+
+func (p *USBDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.ParentPortNumber, &p.InterfaceNumber)
+}
+
+func (p *USBDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, USBSubType, p.ParentPortNumber, p.InterfaceNumber)
+}
+
+// parseUSBText parses USB(Port,Interface).
+func parseUSBText(a *textArgs) DevicePath {
+	return &USBDevicePath{ParentPortNumber: a.u8(0), InterfaceNumber: a.u8(1)}
+}
+
+// USBWWIDDevicePath defines a USB device by its serial number.
+//
+// Section 10.3.4.7 "USB WWID Device Path"
+type USBWWIDDevicePath struct {
+	Head
+
+	// InterfaceNumber is the USB interface number.
+	InterfaceNumber uint16
+
+	// VendorID is the USB vendor ID assigned by USB-IF.
+	VendorID uint16
+
+	// ProductID is the vendor-assigned USB product ID.
+	ProductID uint16
+
+	// SerialNumber is the UTF-16 encoded serial number of the
+	// device, without a terminating null character.
+	SerialNumber []byte `efijson:"utf16"`
+}
+
+func (p *USBWWIDDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *USBWWIDDevicePath) Text() string {
+	return fmt.Sprintf(
+		`UsbWwid(%#x,%#x,%#x,"%s")`,
+		p.VendorID,
+		p.ProductID,
+		p.InterfaceNumber,
+		efireader.UTF16ZBytesToString(p.SerialNumber),
+	)
+}
+
+func (p *USBWWIDDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	if err = fr.ReadFields(&p.InterfaceNumber, &p.VendorID, &p.ProductID); err != nil {
+		return
+	}
+
+	p.SerialNumber, err = io.ReadAll(fr)
+	return
+}
+
+func (p *USBWWIDDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, USBWWIDSubType, p.InterfaceNumber, p.VendorID, p.ProductID, p.SerialNumber)
+}
+
+// parseUSBWWIDText parses UsbWwid(VID,PID,InterfaceNumber,"WWID").
+func parseUSBWWIDText(a *textArgs) DevicePath {
+	serial, err := efireader.EncodeUTF16Z(a.str(3), efireader.UTF16)
+	if err != nil {
+		a.fail(3, err)
+	} else {
+		serial = serial[:len(serial)-2]
+	}
+	return &USBWWIDDevicePath{
+		VendorID:        a.u16(0),
+		ProductID:       a.u16(1),
+		InterfaceNumber: a.u16(2),
+		SerialNumber:    serial,
+	}
+}
+
+// USB device class codes with dedicated text representations.
+const (
+	USBClassAudio       uint8 = 0x01
+	USBClassCDCControl  uint8 = 0x02
+	USBClassHID         uint8 = 0x03
+	USBClassImage       uint8 = 0x06
+	USBClassPrinter     uint8 = 0x07
+	USBClassMassStorage uint8 = 0x08
+	USBClassHub         uint8 = 0x09
+	USBClassCDCData     uint8 = 0x0a
+	USBClassSmartCard   uint8 = 0x0b
+	USBClassVideo       uint8 = 0x0e
+	USBClassDiagnostic  uint8 = 0xdc
+	USBClassWireless    uint8 = 0xe0
+
+	// USBClassApplication is the class of application specific
+	// devices, which are told apart by their subclass.
+	USBClassApplication uint8 = 0xfe
+)
+
+// USB subclass codes of the USBClassApplication class with dedicated
+// text representations.
+const (
+	USBSubClassFirmwareUpdate     uint8 = 0x01
+	USBSubClassIrDABridge         uint8 = 0x02
+	USBSubClassTestAndMeasurement uint8 = 0x03
+)
+
+// usbClassNames maps device classes to the names of their text
+// representations.
+var usbClassNames = map[uint8]string{
+	USBClassAudio:       "UsbAudio",
+	USBClassCDCControl:  "UsbCDCControl",
+	USBClassHID:         "UsbHID",
+	USBClassImage:       "UsbImage",
+	USBClassPrinter:     "UsbPrinter",
+	USBClassMassStorage: "UsbMassStorage",
+	USBClassHub:         "UsbHub",
+	USBClassCDCData:     "UsbCDCData",
+	USBClassSmartCard:   "UsbSmartCard",
+	USBClassVideo:       "UsbVideo",
+	USBClassDiagnostic:  "UsbDiagnostic",
+	USBClassWireless:    "UsbWireless",
+}
+
+// usbSubClassNames maps the subclasses of the USBClassApplication
+// class to the names of their text representations.
+var usbSubClassNames = map[uint8]string{
+	USBSubClassFirmwareUpdate:     "UsbDeviceFirmwareUpdate",
+	USBSubClassIrDABridge:         "UsbIrdaBridge",
+	USBSubClassTestAndMeasurement: "UsbTestAndMeasurement",
+}
+
+// USBClassDevicePath defines a USB device by its class.
+//
+// Section 10.3.4.8 "USB Device Path (Class)"
+type USBClassDevicePath struct {
+	Head
+
+	// VendorID is the USB vendor ID assigned by USB-IF, 0xFFFF
+	// matches any vendor.
+	VendorID uint16
+
+	// ProductID is the vendor-assigned USB product ID, 0xFFFF
+	// matches any product.
+	ProductID uint16
+
+	// DeviceClass is the class code assigned by USB-IF, 0xFF
+	// matches any class.
+	DeviceClass uint8
+
+	// DeviceSubClass is the subclass code assigned by USB-IF,
+	// 0xFF matches any subclass.
+	DeviceSubClass uint8
+
+	// DeviceProtocol is the protocol code assigned by USB-IF,
+	// 0xFF matches any protocol.
+	DeviceProtocol uint8
+}
+
+func (p *USBClassDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *USBClassDevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *USBClassDevicePath) FormatText(flags TextFlags) string {
+	if flags&AllowShortcuts == 0 {
+		return p.classText()
+	}
+
+	if name, ok := usbClassNames[p.DeviceClass]; ok {
+		return fmt.Sprintf("%s(%#x,%#x,%#x,%#x)", name, p.VendorID, p.ProductID, p.DeviceSubClass, p.DeviceProtocol)
+	}
+
+	if p.DeviceClass == USBClassApplication {
+		if name, ok := usbSubClassNames[p.DeviceSubClass]; ok {
+			return fmt.Sprintf("%s(%#x,%#x,%#x)", name, p.VendorID, p.ProductID, p.DeviceProtocol)
+		}
+	}
+
+	return p.classText()
+}
+
+func (p *USBClassDevicePath) classText() string {
+	return fmt.Sprintf(
+		"UsbClass(%#x,%#x,%#x,%#x,%#x)",
+		p.VendorID,
+		p.ProductID,
+		p.DeviceClass,
+		p.DeviceSubClass,
+		p.DeviceProtocol,
+	)
+}
+
+func (p *USBClassDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.VendorID, &p.ProductID, &p.DeviceClass, &p.DeviceSubClass, &p.DeviceProtocol)
+}
+
+func (p *USBClassDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(
+		w,
+		MessagingType,
+		USBClassSubType,
+		p.VendorID,
+		p.ProductID,
+		p.DeviceClass,
+		p.DeviceSubClass,
+		p.DeviceProtocol,
+	)
+}
+
+// parseUSBClassText parses UsbClass(VID,PID,Class,SubClass,Protocol).
+func parseUSBClassText(a *textArgs) DevicePath {
+	return &USBClassDevicePath{
+		VendorID:       a.u16(0),
+		ProductID:      a.u16(1),
+		DeviceClass:    a.u8(2),
+		DeviceSubClass: a.u8(3),
+		DeviceProtocol: a.u8(4),
+	}
+}
+
+// usbClassTextParser returns a parser for the text representation
+// of the given device class, i.e. UsbMassStorage(VID,PID,SubClass,Protocol).
+func usbClassTextParser(class uint8) textParseFn {
+	return func(a *textArgs) DevicePath {
+		return &USBClassDevicePath{
+			VendorID:       a.u16(0),
+			ProductID:      a.u16(1),
+			DeviceClass:    class,
+			DeviceSubClass: a.u8(2),
+			DeviceProtocol: a.u8(3),
+		}
+	}
+}
+
+// usbSubClassTextParser returns a parser for the text representation
+// of the given application specific subclass, i.e.
+// UsbIrdaBridge(VID,PID,Protocol).
+func usbSubClassTextParser(subClass uint8) textParseFn {
+	return func(a *textArgs) DevicePath {
+		return &USBClassDevicePath{
+			VendorID:       a.u16(0),
+			ProductID:      a.u16(1),
+			DeviceClass:    USBClassApplication,
+			DeviceSubClass: subClass,
+			DeviceProtocol: a.u8(2),
+		}
+	}
+}
+
+func init() {
+	for class, name := range usbClassNames {
+		textParsers[name] = usbClassTextParser(class)
+	}
+	for subClass, name := range usbSubClassNames {
+		textParsers[name] = usbSubClassTextParser(subClass)
+	}
+}
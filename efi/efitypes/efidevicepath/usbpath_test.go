@@ -0,0 +1,79 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMessagingDevicePath_USB(t *testing.T) {
+	runNodeTests(t, []nodeTestCase{
+		{
+			"usb",
+			&USBDevicePath{ParentPortNumber: 2},
+			"USB(0x2,0x0)",
+			"03050600 02 00",
+		},
+		{
+			"usb wwid",
+			&USBWWIDDevicePath{VendorID: 0x0781, ProductID: 0x5581, SerialNumber: []byte{'4', 0, 'C', 0, '5', 0, '3', 0}},
+			`UsbWwid(0x781,0x5581,0x0,"4C53")`,
+			"03101200 0000 8107 8155 3400430035003300",
+		},
+		{
+			"usb class alias",
+			&USBClassDevicePath{
+				VendorID:       0x0781,
+				ProductID:      0x5581,
+				DeviceClass:    USBClassMassStorage,
+				DeviceSubClass: 0x06,
+				DeviceProtocol: 0x50,
+			},
+			"UsbMassStorage(0x781,0x5581,0x6,0x50)",
+			"030f0b00 8107 8155 08 06 50",
+		},
+		{
+			"usb class subclass alias",
+			&USBClassDevicePath{
+				VendorID:       0xffff,
+				ProductID:      0xffff,
+				DeviceClass:    USBClassApplication,
+				DeviceSubClass: USBSubClassFirmwareUpdate,
+				DeviceProtocol: 0xff,
+			},
+			"UsbDeviceFirmwareUpdate(0xffff,0xffff,0xff)",
+			"030f0b00 ffff ffff fe 01 ff",
+		},
+		{
+			"usb class",
+			&USBClassDevicePath{VendorID: 0xffff, ProductID: 0xffff, DeviceClass: 0xff, DeviceSubClass: 0xff, DeviceProtocol: 0xff},
+			"UsbClass(0xffff,0xffff,0xff,0xff,0xff)",
+			"030f0b00 ffff ffff ff ff ff",
+		},
+	})
+}
+
+func TestParseText_USB(t *testing.T) {
+	p, err := ParseText("Pci(0,20)/USB(1,0)/UsbHID(0x46d,0xc52b,1,2)/Unit(0)")
+	if err != nil {
+		t.Fatalf("ParseText() error = %v", err)
+	}
+
+	want := "Pci(0x0,0x14)/USB(0x1,0x0)/UsbHID(0x46d,0xc52b,0x1,0x2)/Unit(0x0)"
+	if got := strings.Join(p.AllText(), ","); got != want {
+		t.Errorf("AllText() = %v, want %v", got, want)
+	}
+}
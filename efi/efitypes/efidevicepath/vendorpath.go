@@ -0,0 +1,463 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+// Vendor GUIDs of the vendor-defined Device Path nodes decoded by
+// this package.
+var (
+	// PCANSITerminalGUID identifies a PC-ANSI terminal.
+	PCANSITerminalGUID = efiguid.MustFromString("e0c14753-f9be-11d2-9a0c-0090273fc14d")
+
+	// VT100TerminalGUID identifies a VT-100 terminal.
+	VT100TerminalGUID = efiguid.MustFromString("dfa66065-b419-11d3-9a2d-0090273fc14d")
+
+	// VT100PlusTerminalGUID identifies a VT-100+ terminal.
+	VT100PlusTerminalGUID = efiguid.MustFromString("7baec70b-57e0-4c76-8e87-2f9e28088343")
+
+	// VTUTF8TerminalGUID identifies a VT-UTF8 terminal.
+	VTUTF8TerminalGUID = efiguid.MustFromString("ad15a0d6-8bec-4acf-a073-d01de77e2d88")
+
+	// UARTFlowControlGUID identifies the vendor-defined messaging
+	// Device Path describing the flow control of a UART.
+	UARTFlowControlGUID = efiguid.MustFromString("37499a9d-542f-4c89-a026-35da142094e4")
+
+	// LinuxInitrdMediaGUID identifies the vendor-defined media
+	// Device Path the Linux EFI stub loads its initrd from.
+	LinuxInitrdMediaGUID = efiguid.MustFromString("5568e427-68fc-4f3d-ac74-ca555231cc68")
+)
+
+// RAM disk type GUIDs defined by the specification and used by EDK2
+// for its virtual disks.
+var (
+	// VirtualDiskGUID identifies a volatile virtual disk.
+	VirtualDiskGUID = efiguid.MustFromString("77ab535a-45fc-624b-5560-f7b281d1f96e")
+
+	// VirtualCDGUID identifies a volatile virtual CD.
+	VirtualCDGUID = efiguid.MustFromString("3d5abd30-4175-87ce-6d64-d2ade523c4bb")
+
+	// PersistentVirtualDiskGUID identifies a persistent virtual
+	// disk.
+	PersistentVirtualDiskGUID = efiguid.MustFromString("5cea02c9-4d07-69d3-269f-4496fbe096f9")
+
+	// PersistentVirtualCDGUID identifies a persistent virtual CD.
+	PersistentVirtualCDGUID = efiguid.MustFromString("08018188-42cd-bb48-100f-5387d53ded3d")
+)
+
+// VendorNodeFn returns a new Device Path node decoding the
+// vendor-defined node with the given Head and vendor GUID.
+//
+// The ReadFrom method of the returned node is called with the data
+// following the vendor GUID, while its WriteTo method has to write
+// the complete node including the vendor GUID.
+type VendorNodeFn func(h Head, g efiguid.GUID) DevicePath
+
+type vendorKey struct {
+	t DevicePathType
+	g efiguid.GUID
+}
+
+var vendorNodes = map[vendorKey]VendorNodeFn{}
+
+// RegisterVendorNode registers fn for decoding vendor-defined nodes
+// of the given type with the given vendor GUID.  Valid types are
+// HardwareType, MessagingType and MediaType.
+//
+// RegisterVendorNode is meant to be called from init functions and
+// panics if a decoder for the same type and GUID is registered
+// twice.
+func RegisterVendorNode(t DevicePathType, g efiguid.GUID, fn VendorNodeFn) {
+	key := vendorKey{t: t, g: g}
+	if _, dup := vendorNodes[key]; dup {
+		panic(fmt.Sprintf("efi/devicepath: vendor node %d/%s registered twice", t, g))
+	}
+	vendorNodes[key] = fn
+}
+
+// TextParseFn parses the comma separated arguments of a Device Path
+// node text representation.
+type TextParseFn func(args []string) (DevicePath, error)
+
+// RegisterNodeText registers fn for parsing Device Path node text
+// representations with the given node name, i.e. the part before
+// the opening parenthesis.
+//
+// RegisterNodeText is meant to be called from init functions and
+// panics if a parser for the same name is registered twice.
+func RegisterNodeText(name string, fn TextParseFn) {
+	if _, dup := textParsers[name]; dup {
+		panic(fmt.Sprintf("efi/devicepath: node text %q registered twice", name))
+	}
+	textParsers[name] = func(a *textArgs) DevicePath {
+		a.used = len(a.args)
+		p, err := fn(a.args)
+		if err != nil && a.err == nil {
+			a.err = err
+		}
+		return p
+	}
+}
+
+// parseVendorNode parses a vendor-defined node through the decoder
+// registered for its vendor GUID.  Nodes without a decoder, or whose
+// decoder fails or does not consume the complete node, are read into
+// fallback instead to keep them intact.
+func parseVendorNode(r io.Reader, h Head, fallback DevicePath) (DevicePath, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < len(efiguid.GUID{}) {
+		return &UnrecognizedDevicePath{Head: h, Data: data}, nil
+	}
+
+	var g efiguid.GUID
+	copy(g[:], data)
+
+	if fn, ok := vendorNodes[vendorKey{t: h.Type, g: g}]; ok {
+		p := fn(h, g)
+
+		br := bytes.NewReader(data[len(g):])
+		if _, err := p.ReadFrom(br); err == nil && br.Len() == 0 {
+			return p, nil
+		}
+	}
+
+	if _, err := fallback.ReadFrom(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return fallback, nil
+}
+
+// vendorText formats the generic text representation of
+// vendor-defined nodes.
+func vendorText(name string, g efiguid.GUID, data []byte) string {
+	if len(data) == 0 {
+		return fmt.Sprintf("%s(%s)", name, g)
+	}
+	return fmt.Sprintf("%s(%s,%x)", name, g, data)
+}
+
+// vendorTextParser returns a parser for the generic text
+// representation of vendor-defined nodes, i.e. VenHw(GUID,Data).
+// The resulting node is decoded in the same way as its binary
+// representation would be.
+func vendorTextParser(t DevicePathType, st DevicePathSubType) textParseFn {
+	return func(a *textArgs) DevicePath {
+		g, data := a.guid(0), a.hex(1)
+		if a.err != nil {
+			return nil
+		}
+
+		body := append(g[:], data...)
+		p, err := parseNode(bytes.NewReader(body), Head{Type: t, SubType: st})
+		if err != nil {
+			a.fail(1, err)
+		}
+		return p
+	}
+}
+
+// VendorHardwareDevicePath is a vendor-defined hardware Device Path
+// node without a registered decoder.
+//
+// Section 10.3.2.3 "Vendor Device Path"
+type VendorHardwareDevicePath struct {
+	Head
+
+	// VendorGUID is the Vendor-assigned GUID that defines the
+	// data that follows.
+	VendorGUID efiguid.GUID
+
+	// VendorDefinedData is the Vendor-defined variable size data.
+	VendorDefinedData []byte
+}
+
+func (p *VendorHardwareDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *VendorHardwareDevicePath) Text() string {
+	return vendorText("VenHw", p.VendorGUID, p.VendorDefinedData)
+}
+
+func (p *VendorHardwareDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	if err = fr.ReadFields(&p.VendorGUID); err != nil {
+		return
+	}
+
+	p.VendorDefinedData, err = io.ReadAll(fr)
+	return
+}
+
+func (p *VendorHardwareDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, VendorHardwareSubType, p.VendorGUID, p.VendorDefinedData)
+}
+
+// VendorMessagingDevicePath is a vendor-defined messaging Device
+// Path node without a registered decoder.
+//
+// Section 10.3.4.17 "Vendor-Defined Messaging Device Path"
+type VendorMessagingDevicePath struct {
+	Head
+
+	// VendorGUID is the Vendor-assigned GUID that defines the
+	// data that follows.
+	VendorGUID efiguid.GUID
+
+	// VendorDefinedData is the Vendor-defined variable size data.
+	VendorDefinedData []byte
+}
+
+func (p *VendorMessagingDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *VendorMessagingDevicePath) Text() string {
+	return vendorText("VenMsg", p.VendorGUID, p.VendorDefinedData)
+}
+
+func (p *VendorMessagingDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	fr := efireader.NewFieldReader(r, &n)
+
+	if err = fr.ReadFields(&p.VendorGUID); err != nil {
+		return
+	}
+
+	p.VendorDefinedData, err = io.ReadAll(fr)
+	return
+}
+
+func (p *VendorMessagingDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, VendorMessagingSubType, p.VendorGUID, p.VendorDefinedData)
+}
+
+// terminalNames maps the terminal type GUIDs to the names of their
+// text representations.
+var terminalNames = map[efiguid.GUID]string{
+	PCANSITerminalGUID:    "VenPcAnsi",
+	VT100TerminalGUID:     "VenVt100",
+	VT100PlusTerminalGUID: "VenVt100Plus",
+	VTUTF8TerminalGUID:    "VenUtf8",
+}
+
+// TerminalDevicePath defines the terminal type of a console
+// connected through a UART.  It is encoded as a vendor-defined
+// messaging Device Path identified by the terminal type.
+//
+// Section 10.3.4.17 "Vendor-Defined Messaging Device Path"
+type TerminalDevicePath struct {
+	Head
+
+	// TerminalType is one of PCANSITerminalGUID,
+	// VT100TerminalGUID, VT100PlusTerminalGUID and
+	// VTUTF8TerminalGUID.
+	TerminalType efiguid.GUID
+}
+
+func (p *TerminalDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *TerminalDevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *TerminalDevicePath) FormatText(flags TextFlags) string {
+	if name, ok := terminalNames[p.TerminalType]; ok && flags&AllowShortcuts != 0 {
+		return name + "()"
+	}
+	return vendorText("VenMsg", p.TerminalType, nil)
+}
+
+// ReadFrom reads the node body following the vendor GUID.
+func (p *TerminalDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return 0, nil
+}
+
+func (p *TerminalDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, VendorMessagingSubType, p.TerminalType)
+}
+
+// terminalTextParser returns a parser for the text representation
+// of the given terminal type, i.e. VenVt100().
+func terminalTextParser(g efiguid.GUID) textParseFn {
+	return func(a *textArgs) DevicePath {
+		return &TerminalDevicePath{TerminalType: g}
+	}
+}
+
+// UART flow control types.
+const (
+	UARTFlowControlNone     uint32 = 0
+	UARTFlowControlHardware uint32 = 1
+	UARTFlowControlXonXoff  uint32 = 2
+)
+
+// UARTFlowControlDevicePath defines the flow control of a UART.  It
+// is encoded as a vendor-defined messaging Device Path identified by
+// UARTFlowControlGUID.
+//
+// Section 10.3.4.18 "UART Flow Control Messaging Path"
+type UARTFlowControlDevicePath struct {
+	Head
+
+	// FlowControlMap is the flow control type in its lowest two
+	// bits, all other bits are reserved.
+	FlowControlMap uint32
+}
+
+func (p *UARTFlowControlDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *UARTFlowControlDevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *UARTFlowControlDevicePath) FormatText(flags TextFlags) string {
+	if flags&AllowShortcuts == 0 {
+		var data [4]byte
+		binary.LittleEndian.PutUint32(data[:], p.FlowControlMap)
+		return vendorText("VenMsg", UARTFlowControlGUID, data[:])
+	}
+
+	switch p.FlowControlMap {
+	case UARTFlowControlNone:
+		return "UartFlowCtrl(None)"
+	case UARTFlowControlHardware:
+		return "UartFlowCtrl(Hardware)"
+	case UARTFlowControlXonXoff:
+		return "UartFlowCtrl(XonXoff)"
+	default:
+		return fmt.Sprintf("UartFlowCtrl(%#x)", p.FlowControlMap)
+	}
+}
+
+// ReadFrom reads the node body following the vendor GUID.
+func (p *UARTFlowControlDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.FlowControlMap)
+}
+
+func (p *UARTFlowControlDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MessagingType, VendorMessagingSubType, UARTFlowControlGUID, p.FlowControlMap)
+}
+
+// parseUARTFlowControlText parses UartFlowCtrl(Value).
+func parseUARTFlowControlText(a *textArgs) DevicePath {
+	return &UARTFlowControlDevicePath{FlowControlMap: uint32(a.enum(0, 32, "None", "Hardware", "XonXoff"))}
+}
+
+// LinuxInitrdMediaDevicePath is the vendor-defined media Device
+// Path the Linux EFI stub loads its initrd from.  It carries no data
+// apart from LinuxInitrdMediaGUID.
+type LinuxInitrdMediaDevicePath struct {
+	Head
+}
+
+func (p *LinuxInitrdMediaDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *LinuxInitrdMediaDevicePath) Text() string {
+	return vendorText("VenMedia", LinuxInitrdMediaGUID, nil)
+}
+
+// ReadFrom reads the node body following the vendor GUID.
+func (p *LinuxInitrdMediaDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return 0, nil
+}
+
+func (p *LinuxInitrdMediaDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, VendorMediaSubType, LinuxInitrdMediaGUID)
+}
+
+// virtualMediaNames maps the RAM disk types EDK2 uses as vendor GUIDs
+// of vendor-defined media Device Paths to the names of their text
+// representations.
+var virtualMediaNames = map[efiguid.GUID]string{
+	VirtualDiskGUID: "VirtualDisk",
+	VirtualCDGUID:   "VirtualCD",
+}
+
+// VirtualMediaDevicePath is the vendor-defined media Device Path EDK2
+// identifies virtual disks and CDs with.  It carries no data apart
+// from the vendor GUID, which is the type of the RAM disk.
+type VirtualMediaDevicePath struct {
+	Head
+
+	// DiskType is either VirtualDiskGUID or VirtualCDGUID.
+	DiskType efiguid.GUID
+}
+
+func (p *VirtualMediaDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *VirtualMediaDevicePath) Text() string {
+	return p.FormatText(AllowShortcuts)
+}
+
+func (p *VirtualMediaDevicePath) FormatText(flags TextFlags) string {
+	if name, ok := virtualMediaNames[p.DiskType]; ok && flags&AllowShortcuts != 0 {
+		return name + "()"
+	}
+	return vendorText("VenMedia", p.DiskType, nil)
+}
+
+// ReadFrom reads the node body following the vendor GUID.
+func (p *VirtualMediaDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return 0, nil
+}
+
+func (p *VirtualMediaDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, MediaType, VendorMediaSubType, p.DiskType)
+}
+
+func init() {
+	for g, name := range terminalNames {
+		RegisterVendorNode(MessagingType, g, func(h Head, g efiguid.GUID) DevicePath {
+			return &TerminalDevicePath{Head: h, TerminalType: g}
+		})
+		textParsers[name] = terminalTextParser(g)
+	}
+
+	RegisterVendorNode(MessagingType, UARTFlowControlGUID, func(h Head, _ efiguid.GUID) DevicePath {
+		return &UARTFlowControlDevicePath{Head: h}
+	})
+	RegisterVendorNode(MessagingType, SASDeviceGUID, func(h Head, _ efiguid.GUID) DevicePath {
+		return &SASDevicePath{Head: h}
+	})
+	RegisterVendorNode(MediaType, LinuxInitrdMediaGUID, func(h Head, _ efiguid.GUID) DevicePath {
+		return &LinuxInitrdMediaDevicePath{Head: h}
+	})
+	for g := range virtualMediaNames {
+		RegisterVendorNode(MediaType, g, func(h Head, g efiguid.GUID) DevicePath {
+			return &VirtualMediaDevicePath{Head: h, DiskType: g}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+func TestVendorDevicePath(t *testing.T) {
+	vendorGUID := efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7")
+
+	runNodeTests(t, []nodeTestCase{
+		{
+			"vendor hardware",
+			&VendorHardwareDevicePath{VendorGUID: vendorGUID, VendorDefinedData: []byte{0xaa, 0xbb}},
+			"VenHw(3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,aabb)",
+			"01041600 3f9fd93c2b4beb43ac29f0890a4772b7 aabb",
+		},
+		{
+			"vendor messaging",
+			&VendorMessagingDevicePath{VendorGUID: vendorGUID},
+			"VenMsg(3CD99F3F-4B2B-43EB-AC29-F0890A4772B7)",
+			"030a1400 3f9fd93c2b4beb43ac29f0890a4772b7",
+		},
+		{
+			"vt100",
+			&TerminalDevicePath{TerminalType: VT100TerminalGUID},
+			"VenVt100()",
+			"030a1400 6560a6df19b4d3119a2d0090273fc14d",
+		},
+		{
+			"pc ansi",
+			&TerminalDevicePath{TerminalType: PCANSITerminalGUID},
+			"VenPcAnsi()",
+			"030a1400 5347c1e0bef9d2119a0c0090273fc14d",
+		},
+		{
+			"uart flow control",
+			&UARTFlowControlDevicePath{FlowControlMap: UARTFlowControlXonXoff},
+			"UartFlowCtrl(XonXoff)",
+			"030a1800 9d9a49372f54894ca02635da142094e4 02000000",
+		},
+		{
+			"linux initrd",
+			&LinuxInitrdMediaDevicePath{},
+			"VenMedia(5568E427-68FC-4F3D-AC74-CA555231CC68)",
+			"04031400 27e46855fc683d4fac74ca555231cc68",
+		},
+		{
+			"virtual disk",
+			&VirtualMediaDevicePath{DiskType: VirtualDiskGUID},
+			"VirtualDisk()",
+			"04031400 5a53ab77fc454b625560f7b281d1f96e",
+		},
+		{
+			"virtual cd",
+			&VirtualMediaDevicePath{DiskType: VirtualCDGUID},
+			"VirtualCD()",
+			"04031400 30bd5a3d7541ce876d64d2ade523c4bb",
+		},
+	})
+}
+
+func TestVendorDevicePath_Fallback(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  string
+		want string
+	}{
+		{
+			"decoder does not consume node",
+			"030a1900 9d9a49372f54894ca02635da142094e4 0200000000",
+			"VenMsg(37499A9D-542F-4C89-A026-35DA142094E4,0200000000)",
+		},
+		{
+			"short node",
+			"030a0600 aabb",
+			"Msg(10,aabb)",
+		},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			inp := mustDecodeHex(t, tc.inp+"7fff0400")
+
+			var p DevicePaths
+			if _, err := p.ReadFrom(bytes.NewReader(inp)); err != nil {
+				t.Fatalf("ReadFrom() error = %v", err)
+			}
+			if got := p[0].Text(); got != tc.want {
+				t.Errorf("Text() = %v, want %v", got, tc.want)
+			}
+
+			var buf bytes.Buffer
+			if _, err := p.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+			if !bytes.Equal(buf.Bytes(), inp) {
+				t.Errorf("WriteTo() = %x, want %x", buf.Bytes(), inp)
+			}
+		})
+	}
+}
+
+var testVendorGUID = efiguid.MustFromString("0fe3a4b2-8d35-4ba4-a5a3-5e3e1f0d5c21")
+
+type testVendorDevicePath struct {
+	Head
+
+	Value uint16
+}
+
+func (p *testVendorDevicePath) GetHead() *Head {
+	return &p.Head
+}
+
+func (p *testVendorDevicePath) Text() string {
+	return fmt.Sprintf("TestVendor(%d)", p.Value)
+}
+
+func (p *testVendorDevicePath) ReadFrom(r io.Reader) (n int64, err error) {
+	return efireader.ReadFields(r, &p.Value)
+}
+
+func (p *testVendorDevicePath) WriteTo(w io.Writer) (n int64, err error) {
+	return writeNode(w, HardwareType, VendorHardwareSubType, testVendorGUID, p.Value)
+}
+
+func init() {
+	RegisterVendorNode(HardwareType, testVendorGUID, func(h Head, _ efiguid.GUID) DevicePath {
+		return &testVendorDevicePath{Head: h}
+	})
+	RegisterNodeText("TestVendor", func(args []string) (DevicePath, error) {
+		v, err := parseTextUint(args[0], 16)
+		return &testVendorDevicePath{Value: uint16(v)}, err
+	})
+	RegisterNodeText("TestNil", func(args []string) (DevicePath, error) {
+		return nil, nil
+	})
+}
+
+func TestRegisterVendorNode(t *testing.T) {
+	runNodeTests(t, []nodeTestCase{
+		{
+			"registered",
+			&testVendorDevicePath{Value: 7},
+			"TestVendor(7)",
+			"01041600 b2a4e30f358da44ba5a35e3e1f0d5c21 0700",
+		},
+	})
+
+	p, err := ParseNodeText("VenHw(0FE3A4B2-8D35-4BA4-A5A3-5E3E1F0D5C21,0800)")
+	if err != nil {
+		t.Fatalf("ParseNodeText() error = %v", err)
+	}
+	if got, want := p.Text(), "TestVendor(8)"; got != want {
+		t.Errorf("Text() = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterNodeText_NilNode(t *testing.T) {
+	if _, err := ParseNodeText("TestNil()"); !errors.Is(err, ErrInvalidText) {
+		t.Errorf("ParseNodeText() error = %v, want %v", err, ErrInvalidText)
+	}
+}
+
+func TestRegisterVendorNode_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterVendorNode() did not panic")
+		}
+	}()
+	RegisterVendorNode(MessagingType, SASDeviceGUID, func(h Head, _ efiguid.GUID) DevicePath {
+		return &SASDevicePath{Head: h}
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+// NodeView is the binary representation of a single Device Path
+// node, including its header, as returned by DevicePathView.Next.
+type NodeView []byte
+
+// Head returns the header of the node.
+func (v NodeView) Head() Head {
+	return Head{
+		Type:    DevicePathType(v[0]),
+		SubType: DevicePathSubType(v[1]),
+		Length:  binary.LittleEndian.Uint16(v[2:]),
+	}
+}
+
+// Is reports whether the node has the given type and subtype.
+func (v NodeView) Is(t DevicePathType, st DevicePathSubType) bool {
+	return DevicePathType(v[0]) == t && DevicePathSubType(v[1]) == st
+}
+
+// Body returns the node without its header.  The returned slice
+// shares its memory with the view.
+func (v NodeView) Body() []byte {
+	return v[4:]
+}
+
+// Decode decodes the node.
+func (v NodeView) Decode() (DevicePath, error) {
+	return parseNode(bytes.NewReader(v.Body()), v.Head())
+}
+
+// DevicePathView gives access to the nodes of the binary
+// representation of a Device Path without decoding them up front,
+// for scanning many Device Paths quickly.  Nodes are sliced from the
+// underlying memory as they are visited and only decoded on request.
+//
+//	for v := efidevicepath.DevicePathView(b); !v.Done(); {
+//		var n efidevicepath.NodeView
+//		if n, v, err = v.Next(); err != nil {
+//			return err
+//		}
+//		if n.Is(efidevicepath.MediaType, efidevicepath.HardDriveSubType) {
+//			...
+//		}
+//	}
+type DevicePathView []byte
+
+// Done reports whether all nodes of the view have been visited, that
+// is whether the view is empty.
+func (v DevicePathView) Done() bool {
+	return len(v) == 0
+}
+
+// Next returns the first node of the view and a view of the nodes
+// following it.  The returned view is empty after the End Entire
+// Device Path node.  Errors are of the type *ParseError.
+func (v DevicePathView) Next() (n NodeView, rest DevicePathView, err error) {
+	if len(v) < 4 {
+		return nil, v, &ParseError{Err: ErrNodeTruncated}
+	}
+
+	n = NodeView(v[:4])
+	switch h := n.Head(); {
+	case h.Length < 4:
+		return nil, v, &ParseError{Head: h, Err: ErrNodeTooShort}
+	case int(h.Length) > len(v):
+		return nil, v, &ParseError{Head: h, Err: ErrNodeTruncated}
+	default:
+		n = NodeView(v[:h.Length])
+	}
+
+	if n.Is(EndOfPathType, EndEntireSubType) {
+		return n, nil, nil
+	}
+	return n, v[len(n):], nil
+}
+
+// Decode decodes all nodes of the view, see Decode.
+func (v DevicePathView) Decode(opts DecodeOptions) (DevicePaths, error) {
+	return Decode(v, opts)
+}
+
+// FileName returns the same as the FileName function for the decoded
+// view, without decoding any nodes other than File Path nodes.
+// Decoding stops at the first malformed node.
+func (v DevicePathView) FileName() string {
+	var b strings.Builder
+	for !v.Done() {
+		n, rest, err := v.Next()
+		if err != nil || n.Is(EndOfPathType, EndSingleSubType) {
+			break
+		}
+		v = rest
+
+		if !n.Is(MediaType, FilePathSubType) {
+			if b.Len() > 0 {
+				break
+			}
+			continue
+		}
+
+		name, _, _ := efireader.CutUTF16Null(n.Body())
+		appendPathName(&b, efireader.UTF16ZBytesToString(name))
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDevicePathView_Next(t *testing.T) {
+	v := DevicePathView(mustDecodeHex(t, "010106000001 7f010400 04040a005c0061000000 7fff0400 aa"))
+
+	var got []string
+	for !v.Done() {
+		var (
+			n   NodeView
+			err error
+		)
+		if n, v, err = v.Next(); err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+
+		d, err := n.Decode()
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		if d.GetHead().Length != uint16(len(n)) {
+			t.Errorf("Decode() Length = %d, want %d", d.GetHead().Length, len(n))
+		}
+		got = append(got, NodeText(d, 0))
+	}
+
+	want := []string{"Pci(0x1,0x0)", "", `\a`, ""}
+	if len(got) != len(want) {
+		t.Fatalf("Next() visited %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Next() visited %q, want %q", got, want)
+		}
+	}
+}
+
+func TestDevicePathView_NextErrors(t *testing.T) {
+	tt := []struct {
+		name    string
+		inp     string
+		wantErr error
+	}{
+		{"truncated head", "0101", ErrNodeTruncated},
+		{"short length", "01010200", ErrNodeTooShort},
+		{"truncated node", "0101060000", ErrNodeTruncated},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := DevicePathView(mustDecodeHex(t, tc.inp)).Next()
+
+			var perr *ParseError
+			if !errors.As(err, &perr) || !errors.Is(err, tc.wantErr) {
+				t.Errorf("Next() error = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestDevicePathView_FileName(t *testing.T) {
+	for _, s := range []string{
+		testHDNode + "/" + testFileNode,
+		testHDNode + `/File(\EFI)/File(BOOT)/File(\BOOTX64.EFI)`,
+		testHDNode,
+		testHDNode + "," + testFileNode,
+	} {
+		p := mustParseText(t, s)
+
+		var buf bytes.Buffer
+		if _, err := p.WriteTo(&buf); err != nil {
+			t.Fatalf("WriteTo() error = %v", err)
+		}
+
+		if got, want := DevicePathView(buf.Bytes()).FileName(), FileName(p); got != want {
+			t.Errorf("FileName(%s) = %v, want %v", s, got, want)
+		}
+	}
+}
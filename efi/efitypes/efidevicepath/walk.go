@@ -0,0 +1,106 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"strings"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+// WalkFn is called by Walk for every node of a Device Path together
+// with the index of the instance the node belongs to. Returning false
+// stops the walk.
+type WalkFn func(instance int, d DevicePath) bool
+
+// Walk calls fn for every node of p in order, skipping the End of
+// Device Path nodes separating and terminating its instances.  For a
+// packed list of Device Paths, like a FilePathList, all Device Paths
+// are walked and instances are counted across them.
+func Walk(p DevicePaths, fn WalkFn) {
+	for i, inst := range p.Instances() {
+		for _, d := range inst {
+			if !fn(i, d) {
+				return
+			}
+		}
+	}
+}
+
+// FindNode returns the first node of p having the type T.
+//
+//	hd, ok := efidevicepath.FindNode[*efidevicepath.HardDriveMediaDevicePath](lo.FilePathList)
+func FindNode[T DevicePath](p DevicePaths) (out T, ok bool) {
+	Walk(p, func(_ int, d DevicePath) bool {
+		out, ok = d.(T)
+		return !ok
+	})
+	return
+}
+
+// FindNodes returns all nodes of p having the type T.
+func FindNodes[T DevicePath](p DevicePaths) (out []T) {
+	Walk(p, func(_ int, d DevicePath) bool {
+		if v, ok := d.(T); ok {
+			out = append(out, v)
+		}
+		return true
+	})
+	return
+}
+
+// FileName returns the path name described by the File Path nodes
+// of the first instance of p, i.e. the path of the loader referenced
+// by a load option, ignoring any further packed Device Paths. Path
+// names split across several consecutive nodes are joined with
+// backslashes.  An empty string is returned if the first instance
+// contains no File Path node.
+func FileName(p DevicePaths) string {
+	var b strings.Builder
+	for _, d := range p.Instances()[0] {
+		f, ok := d.(*FilePathDevicePath)
+		if !ok {
+			if b.Len() > 0 {
+				break
+			}
+			continue
+		}
+
+		appendPathName(&b, efireader.UTF16ZBytesToString(f.PathName))
+	}
+	return b.String()
+}
+
+// appendPathName appends the path name of a File Path node to b,
+// separating it from the path names before with a backslash.
+func appendPathName(b *strings.Builder, name string) {
+	if b.Len() > 0 && !strings.HasSuffix(b.String(), `\`) && !strings.HasPrefix(name, `\`) {
+		b.WriteString(`\`)
+	}
+	b.WriteString(name)
+}
+
+// PartitionGUID returns the unique partition GUID of the first GPT
+// partition referenced by a Hard Drive node in p, i.e. the EFI system
+// partition a load option boots from.
+func PartitionGUID(p DevicePaths) (g efiguid.GUID, ok bool) {
+	for _, hd := range FindNodes[*HardDriveMediaDevicePath](p) {
+		if hd.SignatureType == GUIDSignatureType {
+			return efiguid.GUID(hd.PartitionSignature), true
+		}
+	}
+	return
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efidevicepath
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+func mustParseText(t *testing.T, s string) DevicePaths {
+	t.Helper()
+	p, err := ParseText(s)
+	if err != nil {
+		t.Fatalf("ParseText(%q) error = %v", s, err)
+	}
+	return p
+}
+
+func TestWalk(t *testing.T) {
+	p := mustParseText(t, "Pci(0x1,0x0)/Ctrl(0x1),Pci(0x2,0x0)")
+
+	var got []string
+	Walk(p, func(instance int, d DevicePath) bool {
+		got = append(got, fmt.Sprintf("%d:%s", instance, d.Text()))
+		return true
+	})
+
+	want := []string{"0:Pci(0x1,0x0)", "0:Ctrl(0x1)", "1:Pci(0x2,0x0)"}
+	if len(got) != len(want) {
+		t.Fatalf("Walk() visited %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Walk() visited %v, want %v", got, want)
+		}
+	}
+
+	var n int
+	Walk(p, func(int, DevicePath) bool {
+		n++
+		return false
+	})
+	if n != 1 {
+		t.Errorf("Walk() visited %d nodes after stopping, want 1", n)
+	}
+}
+
+func TestWalk_PackedList(t *testing.T) {
+	// A load option FilePathList holding the loader followed by the
+	// initrd the Linux EFI stub is supposed to load.
+	p := mustParseText(t, testHDNode+"/"+testFileNode)
+	p = append(p, mustParseText(t, "VenMedia("+LinuxInitrdMediaGUID.String()+`)/File(\initrd.img)`)...)
+
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	list, err := DecodeList(buf.Bytes(), DecodeOptions{Strict: true})
+	if err != nil {
+		t.Fatalf("DecodeList() error = %v", err)
+	}
+
+	var got []string
+	Walk(list, func(instance int, d DevicePath) bool {
+		got = append(got, fmt.Sprintf("%d:%s", instance, d.Text()))
+		return true
+	})
+	want := []string{
+		"0:" + testHDNode,
+		"0:" + testFileNode,
+		"1:VenMedia(" + LinuxInitrdMediaGUID.String() + ")",
+		`1:File(\initrd.img)`,
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Walk() visited %v, want %v", got, want)
+	}
+
+	if _, ok := FindNode[*LinuxInitrdMediaDevicePath](list); !ok {
+		t.Errorf("FindNode() did not find the initrd node of the second Device Path")
+	}
+	if got, want := FileName(list), `\EFI\BOOT\BOOTX64.EFI`; got != want {
+		t.Errorf("FileName() = %v, want %v", got, want)
+	}
+}
+
+func TestFindNode(t *testing.T) {
+	p := mustParseText(t, testDiskPath+"/"+testHDNode+"/"+testFileNode+",Pci(0x3,0x0)")
+
+	hd, ok := FindNode[*HardDriveMediaDevicePath](p)
+	if !ok || hd.PartitionNumber != 1 {
+		t.Errorf("FindNode() = %v, %v, want partition 1", hd, ok)
+	}
+
+	if _, ok := FindNode[*CDROMDevicePath](p); ok {
+		t.Errorf("FindNode() found a node not present in the Device Path")
+	}
+
+	if got := FindNodes[*PCIDevicePath](p); len(got) != 2 || got[1].Device != 3 {
+		t.Errorf("FindNodes() = %v, want two PCI nodes", got)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tt := []struct {
+		inp  string
+		want string
+	}{
+		{testHDNode + "/" + testFileNode, `\EFI\BOOT\BOOTX64.EFI`},
+		{testHDNode + `/File(\EFI)/File(BOOT)/File(\BOOTX64.EFI)`, `\EFI\BOOT\BOOTX64.EFI`},
+		{testHDNode + `/File(\EFI\)/File(BOOTX64.EFI)`, `\EFI\BOOTX64.EFI`},
+		{testHDNode, ""},
+		{testHDNode + "," + testFileNode, ""},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.inp, func(t *testing.T) {
+			if got := FileName(mustParseText(t, tc.inp)); got != tc.want {
+				t.Errorf("FileName() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPartitionGUID(t *testing.T) {
+	g, ok := PartitionGUID(mustParseText(t, `HD(1,MBR,0x12345678,0x800,0x1000)/HD(2,GPT,3CD99F3F-4B2B-43EB-AC29-F0890A4772B7,0x800,0x1000)`))
+	if want := efiguid.MustFromString("3cd99f3f-4b2b-43eb-ac29-f0890a4772b7"); !ok || g != want {
+		t.Errorf("PartitionGUID() = %v, %v, want %v", g, ok, want)
+	}
+
+	if _, ok := PartitionGUID(mustParseText(t, testFileNode)); ok {
+		t.Errorf("PartitionGUID() found a partition in a Device Path without one")
+	}
+}
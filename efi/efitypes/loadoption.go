@@ -15,11 +15,16 @@
 package efitypes
 
 import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/0x5a17ed/uefi/efi/efireader"
 	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
+	"github.com/0x5a17ed/uefi/efi/efiwriter"
 )
 
 // Attributes describes options for a LoadOption.
@@ -58,6 +63,17 @@ const (
 	CategoryAppAttribute Attributes = 0x00000100
 )
 
+var (
+	ErrFilePathListTooLarge    = errors.New("file path list exceeds maximum length")
+	ErrFilePathListLength      = errors.New("file path list length exceeds the available data")
+	ErrDescriptionUnterminated = errors.New("description is not null terminated")
+	ErrLoadOptionTruncated     = errors.New("load option truncated")
+)
+
+// loadOptionHeaderLength is the length of the Attributes and the
+// FilePathListLength fields preceding the Description.
+const loadOptionHeaderLength = 6
+
 // LoadOption describes an UEFI application being loaded and executed by
 // the Boot Manager.
 //
@@ -91,11 +107,59 @@ type LoadOption struct {
 	OptionalData []byte
 }
 
+// NewLoadOption returns a new LoadOption with the given description,
+// attributes, device paths and optional data.  An empty FilePathList
+// is replaced by a single End Entire Device Path node.  An error is
+// returned if the description can not be encoded as UCS-2, see
+// SetDescription.
+func NewLoadOption(
+	description string,
+	attrs Attributes,
+	filePathList efidevicepath.DevicePaths,
+	optionalData []byte,
+) (*LoadOption, error) {
+	if len(filePathList) == 0 {
+		filePathList = efidevicepath.JoinInstances()
+	}
+	lo := &LoadOption{
+		Attributes:   attrs,
+		FilePathList: filePathList,
+		OptionalData: optionalData,
+	}
+	if err := lo.SetDescription(description); err != nil {
+		return nil, err
+	}
+	return lo, nil
+}
+
 // DescriptionString returns the Description field decoded as a string.
+// Invalid characters are replaced, use DecodeDescription to detect them.
 func (lo *LoadOption) DescriptionString() string {
 	return efireader.UTF16ZBytesToString(lo.Description)
 }
 
+// DecodeDescription returns the Description field decoded as a
+// null terminated UCS-2 string.
+func (lo *LoadOption) DecodeDescription() (string, error) {
+	s, err := efireader.DecodeUTF16Z(lo.Description, efireader.UCS2)
+	if err != nil {
+		return "", fmt.Errorf("LoadOption/Description: %w", err)
+	}
+	return s, nil
+}
+
+// SetDescription sets the Description field to the UCS-2 encoded
+// form of the given string.  The Description is left unchanged if s
+// contains null characters or characters outside of UCS-2.
+func (lo *LoadOption) SetDescription(s string) error {
+	b, err := efireader.EncodeUTF16Z(s, efireader.UCS2)
+	if err != nil {
+		return fmt.Errorf("LoadOption/Description: %w", err)
+	}
+	lo.Description = b
+	return nil
+}
+
 func (lo *LoadOption) ReadFrom(r io.Reader) (n int64, err error) {
 	fr := efireader.NewFieldReader(r, &n)
 
@@ -112,14 +176,210 @@ func (lo *LoadOption) ReadFrom(r io.Reader) (n int64, err error) {
 	}
 
 	if lo.FilePathListLength > 0 {
-		r := io.LimitReader(fr, int64(lo.FilePathListLength))
+		r := &io.LimitedReader{R: fr, N: int64(lo.FilePathListLength)}
 		if _, err = lo.FilePathList.ReadFrom(r); err != nil {
 			err = fmt.Errorf("LoadOption/FilepathList: %w", err)
 			return
 		}
+
+		// The file path list is a packed array of Device Paths,
+		// each terminated by an End Entire Device Path node.
+		for r.N > 0 {
+			paths := len(lo.FilePathList)
+			if _, err = lo.FilePathList.ReadFrom(r); err != nil {
+				if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+					err = fmt.Errorf("LoadOption/FilepathList: %w", err)
+					return
+				}
+
+				// Skip whatever remains of the file path list
+				// if it does not hold a complete Device Path,
+				// it is not part of the optional data.
+				lo.FilePathList = lo.FilePathList[:paths]
+				if _, err = io.Copy(io.Discard, r); err != nil {
+					err = fmt.Errorf("LoadOption/FilepathList: %w", err)
+					return
+				}
+				break
+			}
+		}
 	}
 
 	lo.OptionalData, err = io.ReadAll(fr)
 
 	return
 }
+
+// DecodeLoadOption decodes the binary representation of a LoadOption
+// from b, which may hold untrusted data, using the given options to
+// decode its FilePathList.
+//
+// The FilePathList is decoded as a packed array of Device Paths using
+// efidevicepath.DecodeList.  In strict mode the Description has to be
+// null terminated and the Device Paths have to be well-formed and
+// fill exactly FilePathListLength bytes.  Otherwise, a missing terminator of the
+// Description and a FilePathListLength exceeding the available data
+// are tolerated, with the FilePathList being decoded from whatever
+// data is available.
+//
+// Errors caused by a malformed FilePathList are of the type
+// *efidevicepath.ParseError, with the offset relative to b.
+func DecodeLoadOption(b []byte, opts efidevicepath.DecodeOptions) (*LoadOption, error) {
+	if len(b) < loadOptionHeaderLength {
+		return nil, fmt.Errorf("LoadOption: %w", ErrLoadOptionTruncated)
+	}
+
+	lo := &LoadOption{
+		Attributes:         Attributes(binary.LittleEndian.Uint32(b)),
+		FilePathListLength: binary.LittleEndian.Uint16(b[4:]),
+	}
+	off := loadOptionHeaderLength
+
+	end := off
+	for ; end+1 < len(b); end += 2 {
+		if b[end] == 0 && b[end+1] == 0 {
+			break
+		}
+	}
+	if end+1 < len(b) {
+		end += 2
+	} else if opts.Strict {
+		return nil, fmt.Errorf("LoadOption/Description: offset %d: %w", off, ErrDescriptionUnterminated)
+	} else {
+		end = len(b)
+	}
+	lo.Description = append([]byte(nil), b[off:end]...)
+	off = end
+
+	n := int(lo.FilePathListLength)
+	if n > len(b)-off {
+		if opts.Strict {
+			return nil, fmt.Errorf("LoadOption/FilePathList: offset %d: %w", off, ErrFilePathListLength)
+		}
+		n = len(b) - off
+	}
+
+	var err error
+	if lo.FilePathList, err = efidevicepath.DecodeList(b[off:off+n], opts); err != nil {
+		var perr *efidevicepath.ParseError
+		if errors.As(err, &perr) {
+			perr.Offset += int64(off)
+		}
+		return nil, fmt.Errorf("LoadOption/FilePathList: %w", err)
+	}
+	off += n
+
+	lo.OptionalData = append([]byte{}, b[off:]...)
+	return lo, nil
+}
+
+// WriteTo writes the binary representation of the LoadOption to w.
+//
+// The FilePathListLength field is updated to the actual length of
+// the encoded FilePathList.  An empty FilePathList, as read from a
+// LoadOption with a zero FilePathListLength, is written as is,
+// without an End of Device Path node.
+func (lo *LoadOption) WriteTo(w io.Writer) (n int64, err error) {
+	var b efiwriter.Buffer
+	if _, err = efiwriter.WriteFields(&b, uint32(lo.Attributes)); err != nil {
+		err = fmt.Errorf("LoadOption: %w", err)
+		return
+	}
+	filePathListLength := b.ReserveUint16()
+	b.Write(efiwriter.TerminateUTF16(lo.Description))
+
+	start := b.Len()
+	if len(lo.FilePathList) > 0 {
+		if _, err = lo.FilePathList.WriteTo(&b); err != nil {
+			err = fmt.Errorf("LoadOption/FilePathList: %w", err)
+			return
+		}
+	}
+	if err = filePathListLength.SetLength(start); err != nil {
+		err = fmt.Errorf("LoadOption/FilePathList: %w", ErrFilePathListTooLarge)
+		return
+	}
+	lo.FilePathListLength = uint16(b.Len() - start)
+
+	b.Write(lo.OptionalData)
+	return b.WriteTo(w)
+}
+
+// loadOptionJSON is the JSON representation of a LoadOption.
+type loadOptionJSON struct {
+	Attributes      Attributes                `json:"attributes"`
+	Description     string                    `json:"description"`
+	DescriptionData *string                   `json:"descriptionData,omitempty"`
+	FilePathList    efidevicepath.DevicePaths `json:"filePathList"`
+	OptionalData    string                    `json:"optionalData"`
+}
+
+// MarshalJSON implements json.Marshaler and returns the LoadOption
+// as JSON object of the following form:
+//
+//	{
+//	  "attributes": 1,
+//	  "description": "Linux",
+//	  "filePathList": [{"type": "Media", ...}, {"type": "End", ...}],
+//	  "optionalData": "aabb"
+//	}
+//
+// The description is decoded as UTF-16 text, the nodes of the file
+// path list are encoded as described by efidevicepath.MarshalNodeJSON
+// including the End of Device Path nodes, and the optional
+// data as lowercase hexadecimal string.  FilePathListLength is left
+// out as WriteTo computes it.
+//
+// A description which is not a valid null terminated UTF-16 string,
+// e.g. one holding an unpaired surrogate, is kept as hexadecimal
+// string in "descriptionData" instead, with "description" holding
+// the same text as DescriptionString.
+func (lo *LoadOption) MarshalJSON() ([]byte, error) {
+	out := loadOptionJSON{
+		Attributes:   lo.Attributes,
+		FilePathList: lo.FilePathList,
+		OptionalData: hex.EncodeToString(lo.OptionalData),
+	}
+
+	if len(lo.Description) > 0 {
+		var err error
+		if out.Description, err = efireader.DecodeUTF16Z(lo.Description, efireader.UTF16); err != nil {
+			data := hex.EncodeToString(lo.Description)
+			out.Description, out.DescriptionData = lo.DescriptionString(), &data
+		}
+	}
+	return json.Marshal(&out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (lo *LoadOption) UnmarshalJSON(b []byte) error {
+	var inp loadOptionJSON
+	if err := json.Unmarshal(b, &inp); err != nil {
+		return err
+	}
+
+	optionalData, err := hex.DecodeString(inp.OptionalData)
+	if err != nil {
+		return fmt.Errorf("LoadOption/OptionalData: %w", err)
+	}
+
+	// The description is encoded as UTF-16 as it is decoded by
+	// MarshalJSON, SetDescription would reject surrogate pairs.
+	var description []byte
+	if inp.DescriptionData != nil {
+		description, err = hex.DecodeString(*inp.DescriptionData)
+	} else {
+		description, err = efireader.EncodeUTF16Z(inp.Description, efireader.UTF16)
+	}
+	if err != nil {
+		return fmt.Errorf("LoadOption/Description: %w", err)
+	}
+
+	*lo = LoadOption{
+		Attributes:   inp.Attributes,
+		Description:  description,
+		FilePathList: inp.FilePathList,
+		OptionalData: optionalData,
+	}
+	return nil
+}
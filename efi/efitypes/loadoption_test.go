@@ -18,15 +18,19 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	assertPkg "github.com/stretchr/testify/assert"
 	requirePkg "github.com/stretchr/testify/require"
 	"gotest.tools/v3/golden"
 
+	"github.com/0x5a17ed/uefi/efi/efireader"
 	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
 )
 
 func readHexdump(r io.Reader) ([]byte, error) {
@@ -87,7 +91,7 @@ func TestLoadOption_ReadFrom(t *testing.T) {
 		{"05", args{fileName: "LoadOption05.txt"}, want{
 			n:               45,
 			description:     "TestOption01",
-			filePathStrings: []string{`BBS(5,"",0)`},
+			filePathStrings: []string{`BBS(USB,,0x0)`},
 			optionalData:    []byte{},
 		}, assertPkg.NoError},
 	}
@@ -114,3 +118,288 @@ func TestLoadOption_ReadFrom(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadOption_WriteTo(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, fileName := range []string{"LoadOption80-01.txt", "LoadOption05.txt"} {
+			fileName := fileName
+			t.Run(fileName, func(t *testing.T) {
+				f := golden.Open(t, fileName)
+				defer f.Close()
+
+				inp, err := readHexdump(f)
+				requirePkg.NoError(t, err)
+
+				var lopt efitypes.LoadOption
+				_, err = lopt.ReadFrom(bytes.NewReader(inp))
+				requirePkg.NoError(t, err)
+
+				var buf bytes.Buffer
+				n, err := lopt.WriteTo(&buf)
+				requirePkg.NoError(t, err)
+				assertPkg.Equal(t, int64(len(inp)), n)
+				assertPkg.Equal(t, inp, buf.Bytes())
+			})
+		}
+	})
+
+	t.Run("EmptyFilePathList", func(t *testing.T) {
+		inp, err := hex.DecodeString("01000000" + "0000" + "41000000" + "aa")
+		requirePkg.NoError(t, err)
+
+		var lopt efitypes.LoadOption
+		_, err = lopt.ReadFrom(bytes.NewReader(inp))
+		requirePkg.NoError(t, err)
+		assertPkg.Empty(t, lopt.FilePathList)
+
+		var buf bytes.Buffer
+		_, err = lopt.WriteTo(&buf)
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, inp, buf.Bytes())
+		assertPkg.Equal(t, uint16(0), lopt.FilePathListLength)
+
+		newLopt, err := efitypes.NewLoadOption("A", efitypes.ActiveAttribute, nil, []byte{0xaa})
+		requirePkg.NoError(t, err)
+
+		buf.Reset()
+		_, err = newLopt.WriteTo(&buf)
+		requirePkg.NoError(t, err)
+		want, err := hex.DecodeString("01000000" + "0400" + "41000000" + "7fff0400" + "aa")
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, want, buf.Bytes())
+	})
+
+	t.Run("NewLoadOption", func(t *testing.T) {
+		lopt, err := efitypes.NewLoadOption(
+			"TestOption01",
+			efitypes.ActiveAttribute,
+			efidevicepath.DevicePaths{
+				&efidevicepath.BIOSBootSpecPath{DeviceType: 5},
+			},
+			[]byte{0xaa},
+		)
+		requirePkg.NoError(t, err)
+
+		var buf bytes.Buffer
+		_, err = lopt.WriteTo(&buf)
+		requirePkg.NoError(t, err)
+
+		want, err := hex.DecodeString("01000000" + "0d00" +
+			"5400650073007400" + "4f007000740069006f006e00" + "300031000000" +
+			"050109000500000000" + "7fff0400" + "aa")
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, want, buf.Bytes())
+		assertPkg.Equal(t, uint16(13), lopt.FilePathListLength)
+
+		_, err = efitypes.NewLoadOption("\U0001F600", efitypes.ActiveAttribute, nil, nil)
+		assertPkg.ErrorIs(t, err, efireader.ErrNotUCS2)
+	})
+}
+
+func TestLoadOption_JSON(t *testing.T) {
+	for _, fileName := range []string{"LoadOption80-01.txt", "LoadOption0404-0401.txt", "LoadOption05.txt"} {
+		fileName := fileName
+		t.Run(fileName, func(t *testing.T) {
+			f := golden.Open(t, fileName)
+			defer f.Close()
+
+			inp, err := readHexdump(f)
+			requirePkg.NoError(t, err)
+
+			var lopt efitypes.LoadOption
+			_, err = lopt.ReadFrom(bytes.NewReader(inp))
+			requirePkg.NoError(t, err)
+
+			b, err := json.Marshal(&lopt)
+			requirePkg.NoError(t, err)
+
+			var got efitypes.LoadOption
+			requirePkg.NoError(t, json.Unmarshal(b, &got))
+
+			var want, buf bytes.Buffer
+			_, err = lopt.WriteTo(&want)
+			requirePkg.NoError(t, err)
+			_, err = got.WriteTo(&buf)
+			requirePkg.NoError(t, err)
+			assertPkg.Equal(t, want.Bytes(), buf.Bytes())
+		})
+	}
+
+	t.Run("Schema", func(t *testing.T) {
+		lopt, err := efitypes.NewLoadOption(
+			"TestOption01",
+			efitypes.ActiveAttribute,
+			efidevicepath.DevicePaths{
+				&efidevicepath.BIOSBootSpecPath{DeviceType: 5},
+			},
+			[]byte{0xaa},
+		)
+		requirePkg.NoError(t, err)
+
+		b, err := json.Marshal(lopt)
+		requirePkg.NoError(t, err)
+		assertPkg.JSONEq(t, `{
+			"attributes": 1,
+			"description": "TestOption01",
+			"filePathList": [{
+				"type": "BIOSBoot",
+				"subType": "BIOSBootSpec",
+				"text": "BBS(USB,,0x0)",
+				"fields": {"DeviceType": 5, "StatusFlag": 0, "Description": ""}
+			}],
+			"optionalData": "aa"
+		}`, string(b))
+	})
+}
+
+func TestLoadOption_Description(t *testing.T) {
+	var lopt efitypes.LoadOption
+	requirePkg.NoError(t, lopt.SetDescription("Linux"))
+	assertPkg.Equal(t, []byte("L\x00i\x00n\x00u\x00x\x00\x00\x00"), lopt.Description)
+
+	got, err := lopt.DecodeDescription()
+	requirePkg.NoError(t, err)
+	assertPkg.Equal(t, "Linux", got)
+
+	assertPkg.ErrorIs(t, lopt.SetDescription("\U0001F600"), efireader.ErrNotUCS2)
+	assertPkg.ErrorIs(t, lopt.SetDescription("a\x00b"), efireader.ErrNullCharacter)
+	assertPkg.Equal(t, "Linux", lopt.DescriptionString())
+
+	lopt.Description = []byte{0x3d, 0xd8, 0x00, 0xde, 0x00, 0x00}
+	_, err = lopt.DecodeDescription()
+	assertPkg.ErrorIs(t, err, efireader.ErrNotUCS2)
+
+	b, err := json.Marshal(&lopt)
+	requirePkg.NoError(t, err)
+	var out efitypes.LoadOption
+	requirePkg.NoError(t, json.Unmarshal(b, &out))
+	assertPkg.Equal(t, lopt.Description, out.Description)
+
+	err = json.Unmarshal([]byte(`{"description": "a\u0000b"}`), &out)
+	assertPkg.ErrorIs(t, err, efireader.ErrNullCharacter)
+
+	// Malformed descriptions are kept as they are.
+	for _, inp := range [][]byte{
+		{0x00, 0xd8, 0x00, 0x00},
+		{'a', 0x00},
+		{'a', 0x00, 0x00, 0x00, 'b', 0x00, 0x00, 0x00},
+		{'a', 0x00, 0x00},
+	} {
+		lopt.Description = inp
+		b, err := json.Marshal(&lopt)
+		requirePkg.NoError(t, err)
+		assertPkg.Contains(t, string(b), `"descriptionData"`)
+
+		var out efitypes.LoadOption
+		requirePkg.NoError(t, json.Unmarshal(b, &out))
+		assertPkg.Equal(t, inp, out.Description)
+	}
+}
+
+func TestDecodeLoadOption(t *testing.T) {
+	for _, tc := range []struct {
+		fileName  string
+		strictErr error
+	}{
+		{"LoadOption80-01.txt", nil},
+		{"LoadOption0404-0401.txt", efitypes.ErrFilePathListLength},
+		{"LoadOption05.txt", nil},
+	} {
+		tc := tc
+		t.Run(tc.fileName, func(t *testing.T) {
+			f := golden.Open(t, tc.fileName)
+			defer f.Close()
+
+			inp, err := readHexdump(f)
+			requirePkg.NoError(t, err)
+
+			var want efitypes.LoadOption
+			_, err = want.ReadFrom(bytes.NewReader(inp))
+			requirePkg.NoError(t, err)
+
+			got, err := efitypes.DecodeLoadOption(inp, efidevicepath.DecodeOptions{})
+			requirePkg.NoError(t, err)
+			assertPkg.Equal(t, &want, got)
+
+			got, err = efitypes.DecodeLoadOption(inp, efidevicepath.DecodeOptions{Strict: true})
+			if tc.strictErr != nil {
+				assertPkg.ErrorIs(t, err, tc.strictErr)
+				return
+			}
+			requirePkg.NoError(t, err)
+			assertPkg.Equal(t, &want, got)
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		tt := []struct {
+			name       string
+			inp        string
+			wantErr    error
+			wantOffset int64
+		}{
+			{"truncated", "0100", efitypes.ErrLoadOptionTruncated, -1},
+			{"unterminated description", "01000000 0400 4100", efitypes.ErrDescriptionUnterminated, -1},
+			{"missing end", "01000000 0600 41000000 010106000001", efidevicepath.ErrMissingEnd, 16},
+			{"truncated path", "01000000 0500 41000000 7fff0400aa", efidevicepath.ErrNodeTruncated, 14},
+			{"short node", "01000000 0400 41000000 7fff0000", efidevicepath.ErrNodeTooShort, 10},
+		}
+		for _, tc := range tt {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				inp, err := hex.DecodeString(strings.ReplaceAll(tc.inp, " ", ""))
+				requirePkg.NoError(t, err)
+
+				_, err = efitypes.DecodeLoadOption(inp, efidevicepath.DecodeOptions{Strict: true})
+				requirePkg.ErrorIs(t, err, tc.wantErr)
+
+				var perr *efidevicepath.ParseError
+				if tc.wantOffset >= 0 && assertPkg.ErrorAs(t, err, &perr) {
+					assertPkg.Equal(t, tc.wantOffset, perr.Offset)
+				}
+
+				_, err = efitypes.DecodeLoadOption(inp, efidevicepath.DecodeOptions{})
+				if tc.wantErr == efitypes.ErrLoadOptionTruncated {
+					assertPkg.Error(t, err)
+				} else {
+					assertPkg.NoError(t, err)
+				}
+			})
+		}
+	})
+
+	t.Run("MultiplePaths", func(t *testing.T) {
+		inp, err := hex.DecodeString("01000000" + "1c00" + "41000000" +
+			"04040a005c0061000000" + "7fff0400" + "04040a005c0062000000" + "7fff0400" + "bb")
+		requirePkg.NoError(t, err)
+
+		got, err := efitypes.DecodeLoadOption(inp, efidevicepath.DecodeOptions{Strict: true})
+		requirePkg.NoError(t, err)
+		assertPkg.Len(t, got.FilePathList, 4)
+		assertPkg.Equal(t, []byte{0xbb}, got.OptionalData)
+
+		var lopt efitypes.LoadOption
+		_, err = lopt.ReadFrom(bytes.NewReader(inp))
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, got, &lopt)
+
+		var buf bytes.Buffer
+		_, err = lopt.WriteTo(&buf)
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, inp, buf.Bytes())
+	})
+
+	t.Run("TrailingFilePathData", func(t *testing.T) {
+		inp, err := hex.DecodeString("01000000" + "0500" + "41000000" + "7fff0400aa" + "bb")
+		requirePkg.NoError(t, err)
+
+		got, err := efitypes.DecodeLoadOption(inp, efidevicepath.DecodeOptions{})
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, []byte{0xbb}, got.OptionalData)
+
+		var lopt efitypes.LoadOption
+		_, err = lopt.ReadFrom(bytes.NewReader(inp))
+		requirePkg.NoError(t, err)
+		assertPkg.Equal(t, []byte{0xbb}, lopt.OptionalData)
+	})
+}
@@ -17,17 +17,38 @@
 package efivario
 
 import (
+	"errors"
 	"os"
 
 	"github.com/spf13/afero"
+	"golang.org/x/sys/unix"
 )
 
 const (
 	DefaultEfiPath = "/sys/firmware/efi/efivars"
 )
 
+// checkEfivarfsMount returns ErrNotMounted if there is no directory
+// at path or the directory is merely the mount point sysfs provides
+// for efivarfs.
+func checkEfivarfsMount(path string) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return classify(ErrNotMounted, err)
+		}
+		return nil
+	}
+	if uint32(st.Type) == unix.SYSFS_MAGIC {
+		return ErrNotMounted
+	}
+	return nil
+}
+
 func NewContext(path string) Context {
-	return NewFileSystemContext(afero.NewBasePathFs(afero.NewOsFs(), path))
+	c := NewFileSystemContext(afero.NewBasePathFs(afero.NewOsFs(), path))
+	c.checkMount = func() error { return checkEfivarfsMount(path) }
+	return c
 }
 
 func NewDefaultContext() Context {
@@ -151,6 +151,35 @@ func convertNameGuid(name string, guid efiguid.GUID) (lpName, lpGuid *uint16, er
 	return
 }
 
+// mapWindowsError classifies the error err returned by the firmware
+// environment functions of windows, returning ErrNotFound,
+// ErrInsufficientSpace or an *Error wrapping err.
+func mapWindowsError(err error) error {
+	switch err {
+	case nil:
+		return nil
+	case windows.ERROR_ENVVAR_NOT_FOUND, windows.STATUS_VARIABLE_NOT_FOUND:
+		return ErrNotFound
+	case windows.ERROR_INSUFFICIENT_BUFFER:
+		return ErrInsufficientSpace
+	case windows.ERROR_PRIVILEGE_NOT_HELD, windows.ERROR_ACCESS_DENIED,
+		windows.STATUS_PRIVILEGE_NOT_HELD, windows.STATUS_ACCESS_DENIED:
+		return classify(ErrPermission, err)
+	case windows.ERROR_WRITE_PROTECT:
+		return classify(ErrWriteProtected, err)
+	case windows.ERROR_INVALID_PARAMETER:
+		return classify(ErrInvalidAttributes, err)
+	case windows.ERROR_NO_SYSTEM_RESOURCES, windows.ERROR_DISK_FULL,
+		windows.STATUS_INSUFFICIENT_RESOURCES:
+		return classify(ErrNoSpace, err)
+	case windows.ERROR_INVALID_FUNCTION, windows.STATUS_NOT_IMPLEMENTED:
+		// Returned on legacy BIOS systems which have no variable
+		// service at all.
+		return classify(ErrNotMounted, err)
+	}
+	return err
+}
+
 type sysEnvVarsAPI interface {
 	Get(lpName *uint16, lpGuid *uint16, buf []byte, attrs *uint32) (n uint32, err error)
 
@@ -186,13 +215,13 @@ func (c WindowsContext) VariableNames() (VariableNameIterator, error) {
 	// Try first a null buffer to figure out how large the buffer needs to be.
 	if err := c.api.Enumerate(1, nil, &bufLen); err != nil {
 		if !errors.Is(err, windows.STATUS_BUFFER_TOO_SMALL) {
-			return nil, err
+			return nil, fmt.Errorf("efivario/VariableNames: %w", mapWindowsError(err))
 		}
 	}
 
 	buf := make([]byte, bufLen)
 	if err := c.api.Enumerate(1, &buf[0], &bufLen); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("efivario/VariableNames: %w", mapWindowsError(err))
 	}
 
 	return &wapiVarNameIterator{buf: bytes.NewBuffer(buf)}, nil
@@ -210,7 +239,7 @@ func (c WindowsContext) GetSizeHint(name string, guid efiguid.GUID) (int64, erro
 	var bufLen uint32
 	err = c.api.Query(&uName, &guid, nil, &bufLen, nil)
 	if err != nil && !errors.Is(err, windows.STATUS_BUFFER_TOO_SMALL) {
-		return 0, fmt.Errorf("efivario/GetSizeHint: query(%q): %w", name, err)
+		return 0, fmt.Errorf("efivario/GetSizeHint: query(%q): %w", name, mapWindowsError(err))
 	}
 	return int64(bufLen), nil
 }
@@ -224,12 +253,8 @@ func (c WindowsContext) Get(name string, guid efiguid.GUID, out []byte) (a Attri
 
 	length, err := c.api.Get(lpName, lpGuid, out, (*uint32)(&a))
 	if err != nil {
-		switch err {
-		case windows.ERROR_INSUFFICIENT_BUFFER:
-			err = ErrInsufficientSpace
-		case windows.ERROR_ENVVAR_NOT_FOUND:
-			err = ErrNotFound
-		default:
+		err = mapWindowsError(err)
+		if err != ErrInsufficientSpace && err != ErrNotFound {
 			err = fmt.Errorf("efivario/Get: %w", err)
 		}
 		return
@@ -245,7 +270,7 @@ func (c WindowsContext) Set(name string, guid efiguid.GUID, attributes Attribute
 
 	err = c.api.Set(lpName, lpGuid, value, (uint32)(attributes))
 	if err != nil {
-		return fmt.Errorf("efivario/Set: %w", err)
+		return fmt.Errorf("efivario/Set: %w", mapWindowsError(err))
 	}
 	return nil
 }
@@ -258,7 +283,7 @@ func (c WindowsContext) Delete(name string, guid efiguid.GUID) error {
 
 	err = c.api.Set(lpName, lpGuid, nil, 0)
 	if err != nil {
-		return fmt.Errorf("efivario/Delete: %w", err)
+		return fmt.Errorf("efivario/Delete: %w", mapWindowsError(err))
 	}
 	return nil
 }
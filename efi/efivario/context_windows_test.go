@@ -112,3 +112,26 @@ func TestWindowsContext_VariableNames(t *testing.T) {
 		assert.Equal(t, []string{"Alice", "Bob", "Charlie"}, s)
 	})
 }
+
+func TestMapWindowsError(t *testing.T) {
+	tt := []struct {
+		err  error
+		want error
+	}{
+		{windows.ERROR_ENVVAR_NOT_FOUND, ErrNotFound},
+		{windows.STATUS_VARIABLE_NOT_FOUND, ErrNotFound},
+		{windows.ERROR_INSUFFICIENT_BUFFER, ErrInsufficientSpace},
+		{windows.ERROR_PRIVILEGE_NOT_HELD, ErrPermission},
+		{windows.STATUS_ACCESS_DENIED, ErrPermission},
+		{windows.ERROR_WRITE_PROTECT, ErrWriteProtected},
+		{windows.ERROR_INVALID_PARAMETER, ErrInvalidAttributes},
+		{windows.ERROR_NO_SYSTEM_RESOURCES, ErrNoSpace},
+		{windows.ERROR_INVALID_FUNCTION, ErrNotMounted},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			assert.ErrorIs(t, mapWindowsError(tc.err), tc.want)
+		})
+	}
+}
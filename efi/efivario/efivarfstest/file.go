@@ -0,0 +1,308 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivarfstest
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// write sets the variable of the entry from the data p written to its
+// file, which holds the attributes followed by the value.
+func (fs *Fs) write(key string, p []byte) error {
+	if len(p) < 4 {
+		return syscall.EINVAL
+	}
+	attrs, value := binary.LittleEndian.Uint32(p), p[4:]
+	if attrs&^attributesMask != 0 {
+		return syscall.EINVAL
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key]
+	if !ok {
+		return syscall.ENOENT
+	}
+
+	stored := attrs &^ appendWrite
+	if e.exists && stored != 0 && stored != e.attrs {
+		// The firmware refuses to change the attributes of an
+		// existing variable.
+		return syscall.EINVAL
+	}
+
+	switch {
+	case attrs&appendWrite != 0 && stored != 0:
+		if !e.exists {
+			e.attrs, e.exists = stored, true
+		}
+		e.value = append(e.value, value...)
+	case stored == 0, len(value) == 0:
+		if !e.exists {
+			return syscall.EIO
+		}
+		// efivarfs removes the file of a deleted variable.
+		delete(fs.entries, key)
+	default:
+		e.attrs, e.value, e.exists = stored, append([]byte(nil), value...), true
+	}
+	e.modTime = time.Now()
+	return nil
+}
+
+// entry returns a copy of the entry with the given key.
+func (fs *Fs) entry(key string) (entry, bool) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key]
+	if !ok {
+		return entry{}, false
+	}
+	return *e, true
+}
+
+// File is an open file of Fs.
+type File struct {
+	fs   *Fs
+	name string
+	key  string
+	flag int
+	dir  bool
+
+	// inode is the entry the file was opened for, which keeps its
+	// inode flags accessible after the variable is deleted.
+	inode *entry
+
+	offset int64
+	names  []string
+	listed bool
+	closed bool
+}
+
+// Ensure the afero.File interface is implemented by File.
+var _ afero.File = &File{}
+
+func (f *File) pathError(op string, err error) error {
+	return &os.PathError{Op: op, Path: f.name, Err: err}
+}
+
+// check returns an error if f is closed, is the root directory or was
+// not opened for writing or reading as requested.
+func (f *File) check(op string, write bool) error {
+	switch {
+	case f.closed:
+		return f.pathError(op, os.ErrClosed)
+	case f.dir:
+		return f.pathError(op, syscall.EISDIR)
+	case write && f.flag&(os.O_WRONLY|os.O_RDWR) == 0:
+		return f.pathError(op, syscall.EBADF)
+	case !write && f.flag&os.O_WRONLY != 0:
+		return f.pathError(op, syscall.EBADF)
+	}
+	return nil
+}
+
+// Close closes the file.  Like efivarfs, closing a newly created file
+// which was never written removes it.
+func (f *File) Close() error {
+	if f.closed {
+		return f.pathError("close", os.ErrClosed)
+	}
+	f.closed = true
+
+	if !f.dir {
+		f.fs.mu.Lock()
+		defer f.fs.mu.Unlock()
+
+		if e, ok := f.fs.entries[f.key]; ok && e == f.inode && !e.exists {
+			delete(f.fs.entries, f.key)
+		}
+	}
+	return nil
+}
+
+func (f *File) Name() string {
+	return f.name
+}
+
+func (f *File) Read(p []byte) (n int, err error) {
+	n, err = f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	return
+}
+
+func (f *File) ReadAt(p []byte, off int64) (n int, err error) {
+	if err := f.check("read", false); err != nil {
+		return 0, err
+	}
+
+	e, ok := f.fs.entry(f.key)
+	if !ok {
+		return 0, f.pathError("read", syscall.ENOENT)
+	}
+
+	content := e.content()
+	if off >= int64(len(content)) {
+		return 0, io.EOF
+	}
+	n = copy(p, content[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	if f.closed {
+		return 0, f.pathError("seek", os.ErrClosed)
+	}
+
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		e, _ := f.fs.entry(f.key)
+		offset += e.size()
+	}
+	if offset < 0 {
+		return 0, f.pathError("seek", syscall.EINVAL)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Write sets the variable of the file, expecting p to hold the
+// attributes followed by the value.  Writing the attributes alone
+// deletes the variable unless they include EFI_VARIABLE_APPEND_WRITE.
+func (f *File) Write(p []byte) (int, error) {
+	if err := f.check("write", true); err != nil {
+		return 0, err
+	}
+	if err := f.fs.write(f.key, p); err != nil {
+		return 0, f.pathError("write", err)
+	}
+	return len(p), nil
+}
+
+// WriteAt writes p like Write, since efivarfs ignores the offset.
+func (f *File) WriteAt(p []byte, _ int64) (int, error) {
+	return f.Write(p)
+}
+
+func (f *File) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *File) Readdir(count int) ([]os.FileInfo, error) {
+	names, err := f.Readdirnames(count)
+
+	infos := make([]os.FileInfo, 0, len(names))
+	for _, name := range names {
+		if fi, err := f.fs.Stat(name); err == nil {
+			infos = append(infos, fi)
+		}
+	}
+	return infos, err
+}
+
+func (f *File) Readdirnames(n int) ([]string, error) {
+	switch {
+	case f.closed:
+		return nil, f.pathError("readdirent", os.ErrClosed)
+	case !f.dir:
+		return nil, f.pathError("readdirent", syscall.ENOTDIR)
+	}
+
+	if !f.listed {
+		f.names, f.listed = f.fs.names(), true
+	}
+
+	if n <= 0 {
+		names := f.names
+		f.names = nil
+		return names, nil
+	}
+	if len(f.names) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(f.names) {
+		n = len(f.names)
+	}
+	names := f.names[:n]
+	f.names = f.names[n:]
+	return names, nil
+}
+
+func (f *File) Stat() (os.FileInfo, error) {
+	if f.closed {
+		return nil, f.pathError("stat", os.ErrClosed)
+	}
+	return f.fs.Stat(f.name)
+}
+
+// Sync fails with EINVAL, since efivarfs does not implement fsync.
+func (f *File) Sync() error {
+	return f.pathError("sync", syscall.EINVAL)
+}
+
+func (f *File) Truncate(int64) error {
+	return f.pathError("truncate", syscall.EPERM)
+}
+
+// GetFlags returns the inode flags of the file, standing in for the
+// FS_IOC_GETFLAGS ioctl.
+func (f *File) GetFlags() (uint32, error) {
+	if f.closed {
+		return 0, f.pathError("ioctl", os.ErrClosed)
+	}
+
+	if f.dir {
+		return 0, nil
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	if f.inode.immutable {
+		return ImmutableFlag, nil
+	}
+	return 0, nil
+}
+
+// SetFlags sets the inode flags of the file, standing in for the
+// FS_IOC_SETFLAGS ioctl.  Like efivarfs it supports ImmutableFlag
+// only.
+func (f *File) SetFlags(flags uint32) error {
+	switch {
+	case f.closed:
+		return f.pathError("ioctl", os.ErrClosed)
+	case f.dir, flags&^ImmutableFlag != 0:
+		return f.pathError("ioctl", syscall.EOPNOTSUPP)
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+
+	f.inode.immutable = flags&ImmutableFlag != 0
+	return nil
+}
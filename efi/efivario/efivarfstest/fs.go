@@ -0,0 +1,342 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package efivarfstest provides a fake efivarfs file system for
+// testing code accessing variables through it without root
+// privileges or a UEFI system.
+//
+// The fake follows the semantics of the efivarfs driver of the Linux
+// kernel: each file is named after the variable and its vendor GUID,
+// which is matched case-insensitively, and holds the 4-byte attributes
+// of the variable followed by its value.  Each write sets the
+// variable, writing the attributes alone deletes it and the immutable
+// flag is supported through the GetFlags and SetFlags methods of its
+// files, standing in for the FS_IOC_GETFLAGS and FS_IOC_SETFLAGS
+// ioctls.
+package efivarfstest
+
+import (
+	"encoding/binary"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// ImmutableFlag is the FS_IMMUTABLE_FL inode flag.
+	ImmutableFlag uint32 = 0x00000010
+
+	// attributesMask are the attributes accepted by efivarfs.
+	attributesMask uint32 = 0x0000007f
+
+	// appendWrite is the EFI_VARIABLE_APPEND_WRITE attribute.
+	appendWrite uint32 = 0x00000040
+
+	// guidLen is the length of a GUID in its text representation.
+	guidLen = 36
+)
+
+// globalVariableGUID is the vendor GUID of the variables defined by
+// the specification.
+const globalVariableGUID = "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// entry is a file of the file system.
+type entry struct {
+	// name is the file name the entry was created with.
+	name string
+
+	// attrs and value hold the variable, which is set if exists is.
+	attrs  uint32
+	value  []byte
+	exists bool
+
+	immutable bool
+	modTime   time.Time
+}
+
+// size returns the size of the file, which is zero until the variable
+// is written.
+func (e *entry) size() int64 {
+	if !e.exists {
+		return 0
+	}
+	return int64(4 + len(e.value))
+}
+
+// content returns the content of the file.
+func (e *entry) content() []byte {
+	if !e.exists {
+		return nil
+	}
+	b := make([]byte, 4, e.size())
+	binary.LittleEndian.PutUint32(b, e.attrs)
+	return append(b, e.value...)
+}
+
+// Fs is an afero.Fs emulating an efivarfs mount.
+type Fs struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+// Ensure the afero.Fs interface is implemented by Fs.
+var _ afero.Fs = &Fs{}
+
+// New returns an empty Fs.
+func New() *Fs {
+	return &Fs{entries: make(map[string]*entry)}
+}
+
+// validName reports whether name consists of a variable name followed
+// by a dash and a vendor GUID, as required by efivarfs.
+func validName(name string) bool {
+	if len(name) < guidLen+2 || name[len(name)-guidLen-1] != '-' {
+		return false
+	}
+
+	for i, c := range name[len(name)-guidLen:] {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// key returns the key of the entry of the file name, which has the
+// GUID in lower case.
+func key(name string) string {
+	if len(name) < guidLen {
+		return name
+	}
+	i := len(name) - guidLen
+	return name[:i] + strings.ToLower(name[i:])
+}
+
+// cleanName returns the name of the file relative to the root
+// directory, which is the empty string.
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+// isRemovable reports whether the variable of a newly created file can
+// be removed, which efivarfs denies for the variables of the
+// specification it knows by making them immutable.
+func isRemovable(name string) bool {
+	return strings.ToLower(name[len(name)-guidLen:]) != globalVariableGUID
+}
+
+func (fs *Fs) Name() string {
+	return "efivarfs"
+}
+
+// SetVariable sets the variable of the file name like the firmware
+// does, bypassing the write semantics and the immutable flag of
+// efivarfs.  New files are made immutable if efivarfs would do so.
+func (fs *Fs) SetVariable(name string, attrs uint32, value []byte) error {
+	name = cleanName(name)
+	if !validName(name) {
+		return &os.PathError{Op: "set", Path: name, Err: syscall.EINVAL}
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key(name)]
+	if !ok {
+		e = &entry{name: name, immutable: !isRemovable(name)}
+		fs.entries[key(name)] = e
+	}
+	e.attrs, e.value, e.exists = attrs, append([]byte(nil), value...), true
+	e.modTime = time.Now()
+	return nil
+}
+
+// SetImmutable sets or clears the immutable flag of the file name.
+func (fs *Fs) SetImmutable(name string, immutable bool) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key(cleanName(name))]
+	if !ok {
+		return &os.PathError{Op: "chattr", Path: name, Err: syscall.ENOENT}
+	}
+	e.immutable = immutable
+	return nil
+}
+
+func (fs *Fs) Create(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+func (fs *Fs) Mkdir(name string, _ os.FileMode) error {
+	return &os.PathError{Op: "mkdir", Path: name, Err: syscall.EPERM}
+}
+
+func (fs *Fs) MkdirAll(name string, _ os.FileMode) error {
+	if cleanName(name) == "" {
+		return nil
+	}
+	return &os.PathError{Op: "mkdir", Path: name, Err: syscall.EPERM}
+}
+
+func (fs *Fs) Open(name string) (afero.File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (fs *Fs) OpenFile(name string, flag int, _ os.FileMode) (afero.File, error) {
+	name = cleanName(name)
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+
+	if name == "" {
+		if writable {
+			return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+		}
+		return &File{fs: fs, dir: true}, nil
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key(name)]
+	switch {
+	case !ok && flag&os.O_CREATE == 0:
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.ENOENT}
+	case !ok && !validName(name):
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EINVAL}
+	case !ok:
+		e = &entry{name: name, immutable: !isRemovable(name), modTime: time.Now()}
+		fs.entries[key(name)] = e
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EEXIST}
+	case writable && e.immutable:
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EPERM}
+	}
+
+	return &File{fs: fs, name: name, key: key(name), flag: flag, inode: e}, nil
+}
+
+func (fs *Fs) Remove(name string) error {
+	name = cleanName(name)
+	if name == "" {
+		return &os.PathError{Op: "remove", Path: name, Err: syscall.EBUSY}
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key(name)]
+	switch {
+	case !ok:
+		return &os.PathError{Op: "remove", Path: name, Err: syscall.ENOENT}
+	case e.immutable:
+		return &os.PathError{Op: "remove", Path: name, Err: syscall.EPERM}
+	}
+	delete(fs.entries, key(name))
+	return nil
+}
+
+func (fs *Fs) RemoveAll(name string) error {
+	if err := fs.Remove(name); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (fs *Fs) Rename(oldname, _ string) error {
+	return &os.PathError{Op: "rename", Path: oldname, Err: syscall.EPERM}
+}
+
+func (fs *Fs) Stat(name string) (os.FileInfo, error) {
+	name = cleanName(name)
+	if name == "" {
+		return &fileInfo{dir: true}, nil
+	}
+
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	e, ok := fs.entries[key(name)]
+	if !ok {
+		return nil, &os.PathError{Op: "stat", Path: name, Err: syscall.ENOENT}
+	}
+	return &fileInfo{name: e.name, size: e.size(), modTime: e.modTime}, nil
+}
+
+func (fs *Fs) Chmod(name string, _ os.FileMode) error {
+	_, err := fs.Stat(name)
+	return err
+}
+
+func (fs *Fs) Chown(name string, _, _ int) error {
+	_, err := fs.Stat(name)
+	return err
+}
+
+func (fs *Fs) Chtimes(name string, _ time.Time, _ time.Time) error {
+	_, err := fs.Stat(name)
+	return err
+}
+
+// names returns the sorted names of all files.
+func (fs *Fs) names() []string {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	names := make([]string, 0, len(fs.entries))
+	for _, e := range fs.entries {
+		names = append(names, e.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// fileInfo describes a file of Fs.
+type fileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (fi *fileInfo) Name() string {
+	if fi.dir {
+		return "/"
+	}
+	return fi.name
+}
+
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.dir }
+func (fi *fileInfo) Sys() interface{}   { return nil }
+
+func (fi *fileInfo) Mode() os.FileMode {
+	if fi.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
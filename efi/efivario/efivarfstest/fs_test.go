@@ -0,0 +1,163 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivarfstest
+
+import (
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testName   = "Test-3cd99f3f-4b2b-43eb-ac29-f0890a4772b7"
+	globalName = "BootNext-8be4df61-93ca-11d2-aa0d-00e098032b8c"
+)
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func writeFile(fs afero.Fs, name string, data []byte) error {
+	f, err := fs.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(data)
+	return err
+}
+
+func TestFs_Write(t *testing.T) {
+	tt := []struct {
+		name    string
+		writes  []string
+		want    string
+		wantErr error
+	}{
+		{"set", []string{"07000000 0102"}, "07000000 0102", nil},
+		{"overwrite", []string{"07000000 0102", "07000000 03"}, "07000000 03", nil},
+		{"append", []string{"07000000 0102", "47000000 03"}, "07000000 010203", nil},
+		{"append new", []string{"47000000 01"}, "07000000 01", nil},
+		{"short", []string{"070000"}, "", syscall.EINVAL},
+		{"unknown attributes", []string{"80000000 01"}, "", syscall.EINVAL},
+		{"changed attributes", []string{"07000000 01", "03000000 02"}, "07000000 01", syscall.EINVAL},
+		{"delete", []string{"07000000 01", "07000000"}, "", nil},
+		{"delete nonexistent", []string{"07000000"}, "", syscall.EIO},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			fs := New()
+
+			var err error
+			for _, w := range tc.writes {
+				if err = writeFile(fs, testName, mustHex(w)); err != nil {
+					break
+				}
+			}
+			assert.ErrorIs(t, err, tc.wantErr)
+
+			got, err := afero.ReadFile(fs, testName)
+			if tc.want == "" {
+				assert.ErrorIs(t, err, syscall.ENOENT)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, mustHex(tc.want), got)
+
+			fi, err := fs.Stat(testName)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(got)), fi.Size())
+		})
+	}
+}
+
+func TestFs_Names(t *testing.T) {
+	fs := New()
+
+	err := writeFile(fs, "Test", mustHex("07000000 01"))
+	assert.ErrorIs(t, err, syscall.EINVAL)
+	err = writeFile(fs, "Test-3cd99f3f-4b2b-43eb-ac29-f0890a4772bx", mustHex("07000000 01"))
+	assert.ErrorIs(t, err, syscall.EINVAL)
+
+	require.NoError(t, writeFile(fs, testName, mustHex("07000000 01")))
+	require.NoError(t, writeFile(fs, testName[:5]+strings.ToUpper(testName[5:]), mustHex("07000000 02")))
+
+	got, err := afero.ReadFile(fs, "/Test-3CD99F3F-4B2B-43EB-AC29-F0890A4772B7")
+	require.NoError(t, err)
+	assert.Equal(t, mustHex("07000000 02"), got)
+
+	_, err = fs.Stat("test-3cd99f3f-4b2b-43eb-ac29-f0890a4772b7")
+	assert.ErrorIs(t, err, syscall.ENOENT)
+
+	// Files created without writing a variable are removed again.
+	f, err := fs.Create("Empty-3cd99f3f-4b2b-43eb-ac29-f0890a4772b7")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	d, err := fs.Open("")
+	require.NoError(t, err)
+	defer d.Close()
+
+	names, err := d.Readdirnames(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testName}, names)
+
+	_, err = d.Readdirnames(1)
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestFs_Immutable(t *testing.T) {
+	fs := New()
+	require.NoError(t, fs.SetVariable(globalName, 7, []byte{1, 0}))
+	require.NoError(t, writeFile(fs, testName, mustHex("07000000 01")))
+
+	err := writeFile(fs, globalName, mustHex("07000000 0200"))
+	assert.ErrorIs(t, err, syscall.EPERM)
+	assert.ErrorIs(t, fs.Remove(globalName), syscall.EPERM)
+
+	f, err := fs.Open(globalName)
+	require.NoError(t, err)
+	defer f.Close()
+
+	fl := f.(interface {
+		GetFlags() (uint32, error)
+		SetFlags(uint32) error
+	})
+
+	got, err := fl.GetFlags()
+	require.NoError(t, err)
+	assert.Equal(t, ImmutableFlag, got)
+
+	assert.ErrorIs(t, fl.SetFlags(ImmutableFlag|0x1), syscall.EOPNOTSUPP)
+	require.NoError(t, fl.SetFlags(0))
+	require.NoError(t, writeFile(fs, globalName, mustHex("07000000 0200")))
+	require.NoError(t, fs.Remove(globalName))
+
+	require.NoError(t, fs.SetImmutable(testName, true))
+	assert.ErrorIs(t, fs.Remove(testName), syscall.EPERM)
+}
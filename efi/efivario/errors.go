@@ -0,0 +1,83 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivario
+
+import (
+	"errors"
+)
+
+// Errors classifying the failures of Context operations, in addition
+// to ErrNotFound and ErrInsufficientSpace.  Errors returned by the
+// Context implementations match them using errors.Is while still
+// wrapping the underlying platform error.
+var (
+	// ErrPermission indicates the caller lacks the privileges to
+	// access the variable service, e.g. because it is not running
+	// as root or Administrator.
+	ErrPermission = errors.New("permission denied")
+
+	// ErrWriteProtected indicates the variable can not be changed,
+	// because it is marked as immutable or the firmware refuses to
+	// change it.
+	ErrWriteProtected = errors.New("variable is write-protected")
+
+	// ErrInvalidAttributes indicates the firmware rejected the
+	// attributes or the size of the value of the variable.
+	ErrInvalidAttributes = errors.New("invalid variable attributes")
+
+	// ErrNoSpace indicates the firmware ran out of storage for
+	// variables.
+	ErrNoSpace = errors.New("out of variable storage")
+
+	// ErrNotMounted indicates the variable service is unavailable,
+	// because efivarfs is not mounted or the system was not booted
+	// through UEFI.
+	ErrNotMounted = errors.New("efivarfs is not mounted")
+
+	// ErrReadOnly indicates the variable service only allows
+	// reading variables, e.g. because efivarfs is mounted read-only.
+	ErrReadOnly = errors.New("variable service is read-only")
+)
+
+// Error wraps an error of the platform's variable service together
+// with its classification.
+type Error struct {
+	// Kind is one of the errors of this package classifying Err.
+	Kind error
+
+	// Err is the error returned by the platform.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the classification of the error.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// classify wraps err into an *Error of the given kind.
+func classify(kind error, err error) error {
+	if errors.Is(err, kind) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
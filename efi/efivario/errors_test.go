@@ -0,0 +1,121 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivario
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+// errFs is a file system failing every operation with err.
+type errFs struct {
+	afero.Fs
+	err error
+}
+
+func (f errFs) Open(name string) (afero.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: f.err}
+}
+
+func (f errFs) OpenFile(name string, _ int, _ os.FileMode) (afero.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: f.err}
+}
+
+func (f errFs) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: f.err}
+}
+
+func (f errFs) Stat(name string) (os.FileInfo, error) {
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: f.err}
+}
+
+func TestError(t *testing.T) {
+	err := fmt.Errorf("efivario/set: %w", classify(ErrNoSpace, syscall.ENOSPC))
+	assert.ErrorIs(t, err, ErrNoSpace)
+	assert.ErrorIs(t, err, syscall.ENOSPC)
+	assert.NotErrorIs(t, err, ErrPermission)
+	assert.EqualError(t, err, "efivario/set: out of variable storage: no space left on device")
+
+	var e *Error
+	if assert.ErrorAs(t, err, &e) {
+		assert.Equal(t, ErrNoSpace, e.Kind)
+	}
+
+	assert.Same(t, e, classify(ErrNoSpace, e))
+}
+
+func TestFsContext_mapError(t *testing.T) {
+	tt := []struct {
+		stage fsStage
+		err   error
+		want  error
+	}{
+		{stageOpen, syscall.ENOENT, ErrNotFound},
+		{stageOpen, fs.ErrNotExist, ErrNotFound},
+		{stageIO, syscall.ENOSPC, ErrNoSpace},
+		{stageIO, syscall.EINVAL, ErrInvalidAttributes},
+		{stageIO, syscall.EROFS, ErrWriteProtected},
+		{stageIO, syscall.EACCES, ErrWriteProtected},
+		{stageOpen, syscall.EROFS, ErrReadOnly},
+		{stageOpen, syscall.EPERM, ErrWriteProtected},
+		{stageOpen, syscall.EACCES, ErrPermission},
+		{stageProtect, syscall.EPERM, ErrPermission},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(fmt.Sprintf("%d/%s", tc.stage, tc.err), func(t *testing.T) {
+			got := FsContext{}.mapError(tc.stage, tc.err)
+			assert.ErrorIs(t, got, tc.want)
+			if tc.want != ErrNotFound {
+				assert.ErrorIs(t, got, tc.err)
+			}
+		})
+	}
+
+	t.Run("unclassified", func(t *testing.T) {
+		for _, err := range []error{syscall.EINTR, syscall.EAGAIN} {
+			assert.Equal(t, err, FsContext{}.mapError(stageIO, err))
+		}
+	})
+
+	t.Run("checkMount", func(t *testing.T) {
+		c := FsContext{checkMount: func() error { return ErrNotMounted }}
+		assert.ErrorIs(t, c.mapError(stageOpen, syscall.ENOENT), ErrNotMounted)
+		assert.ErrorIs(t, c.mapError(stageIO, syscall.ENOSPC), ErrNoSpace)
+	})
+}
+
+func TestFsContext_Errors(t *testing.T) {
+	c := NewFileSystemContext(errFs{Fs: afero.NewMemMapFs(), err: syscall.EACCES})
+
+	_, _, err := c.Get("Test", testGuid, nil)
+	assert.ErrorIs(t, err, ErrPermission)
+	assert.ErrorIs(t, c.Set("Test", testGuid, NonVolatile, nil), ErrPermission)
+	assert.ErrorIs(t, c.Delete("Test", testGuid), ErrPermission)
+
+	_, err = c.GetSizeHint("Test", testGuid)
+	assert.ErrorIs(t, err, ErrPermission)
+
+	c = NewFileSystemContext(errFs{Fs: afero.NewMemMapFs(), err: syscall.ENOENT})
+	_, err = c.VariableNames()
+	assert.ErrorIs(t, err, ErrNotMounted)
+	assert.ErrorIs(t, err, syscall.ENOENT)
+}
@@ -94,6 +94,10 @@ func getFileName(name string, guid efiguid.GUID) string {
 // the EFI variable service.
 type FsContext struct {
 	fs afero.Fs
+
+	// checkMount, if set, returns ErrNotMounted if the file system
+	// is not backed by efivarfs.
+	checkMount func() error
 }
 
 // Ensure the public facing API in Context is implemented by FsContext.
@@ -106,7 +110,15 @@ func (c FsContext) Close() error {
 func (c FsContext) VariableNames() (VariableNameIterator, error) {
 	f, err := c.fs.Open("")
 	if err != nil {
-		return nil, err
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOENT) {
+			return nil, classify(ErrNotMounted, err)
+		}
+		return nil, c.mapError(stageOpen, err)
+	}
+	if c.checkMount != nil {
+		if err := c.checkMount(); err != nil {
+			return nil, multierr.Append(err, f.Close())
+		}
 	}
 	return &fsVarNameIterator{f: f}, nil
 }
@@ -114,19 +126,19 @@ func (c FsContext) VariableNames() (VariableNameIterator, error) {
 func (c FsContext) readEfiVarFileName(name string, out []byte) (a Attributes, n int, err error) {
 	f, err := c.fs.Open(name)
 	if err != nil {
-		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOENT) {
-			// Overwrite error with a custom error type.
-			err = ErrNotFound
-		}
+		err = c.mapError(stageOpen, err)
 		return
 	}
 	defer multierr.AppendInvoke(&err, multierr.Close(f))
 
 	if err = binary.Read(f, binary.LittleEndian, &a); err != nil {
+		err = c.mapError(stageIO, err)
 		return
 	}
 
-	if n, err = f.Read(out); err != nil {
+	// Empty variables report EOF right away.
+	if n, err = f.Read(out); err != nil && err != io.EOF {
+		err = c.mapError(stageIO, err)
 		return
 	}
 
@@ -155,7 +167,7 @@ func (c FsContext) writeEfiVarFileName(name string, value []byte, attrs Attribut
 
 	guard, err := openSafeguard(c.fs, name)
 	if err != nil {
-		return fmt.Errorf("guard open: %w", err)
+		return fmt.Errorf("guard open: %w", c.mapError(stageOpen, err))
 	}
 	if guard != nil {
 		defer multierr.AppendInvoke(&err, multierr.Invoke(func() error {
@@ -168,7 +180,7 @@ func (c FsContext) writeEfiVarFileName(name string, value []byte, attrs Attribut
 
 	wasProtected, err := guard.disable()
 	if err != nil {
-		return fmt.Errorf("disable protection: %w", err)
+		return fmt.Errorf("disable protection: %w", c.mapError(stageProtect, err))
 	}
 	if wasProtected {
 		defer multierr.AppendInvoke(&err, multierr.Invoke(func() error {
@@ -186,12 +198,12 @@ func (c FsContext) writeEfiVarFileName(name string, value []byte, attrs Attribut
 
 	f, err := c.fs.OpenFile(name, flags, 0644)
 	if err != nil {
-		return err
+		return c.mapError(stageOpen, err)
 	}
 	defer multierr.AppendInvoke(&err, multierr.Close(f))
 
 	if _, err := buf.WriteTo(f); err != nil {
-		return err
+		return c.mapError(stageIO, err)
 	}
 
 	if err := f.Sync(); err != nil {
@@ -213,7 +225,7 @@ func (c FsContext) writeEfiVarFileName(name string, value []byte, attrs Attribut
 func (c FsContext) deleteEfiFile(name string) error {
 	guard, err := openSafeguard(c.fs, name)
 	if err != nil {
-		return fmt.Errorf("guard open: %w", err)
+		return fmt.Errorf("guard open: %w", c.mapError(stageOpen, err))
 	}
 	if guard != nil {
 		defer multierr.AppendInvoke(&err, multierr.Invoke(func() error {
@@ -225,15 +237,11 @@ func (c FsContext) deleteEfiFile(name string) error {
 	}
 
 	if _, err := guard.disable(); err != nil {
-		return fmt.Errorf("guard disable: %w", err)
+		return fmt.Errorf("guard disable: %w", c.mapError(stageProtect, err))
 	}
 
 	if err := c.fs.Remove(name); err != nil {
-		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOENT) {
-			// Overwrite error with a custom error type.
-			err = ErrNotFound
-		}
-		return fmt.Errorf("remove: %w", err)
+		return fmt.Errorf("remove: %w", c.mapError(stageOpen, err))
 	}
 	return nil
 }
@@ -241,7 +249,7 @@ func (c FsContext) deleteEfiFile(name string) error {
 func (c FsContext) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
 	fi, err := c.fs.Stat(getFileName(name, guid))
 	if err != nil {
-		return 0, err
+		return 0, fmt.Errorf("efivario/hint: %w", c.mapError(stageOpen, err))
 	}
 	return fi.Size() - 4, nil
 }
@@ -268,6 +276,61 @@ func (c FsContext) Delete(name string, guid efiguid.GUID) (err error) {
 	return
 }
 
+// fsStage is the stage of a file system operation an error occurred
+// in, since efivarfs reports different failures with the same errno
+// depending on it.
+type fsStage uint8
+
+const (
+	// stageOpen covers opening, creating, removing and looking up
+	// the file of a variable.
+	stageOpen fsStage = iota
+
+	// stageIO covers reading and writing an opened file, which
+	// efivarfs turns into GetVariable and SetVariable calls.
+	stageIO
+
+	// stageProtect covers changing the immutable flag of a file.
+	stageProtect
+)
+
+// mapError classifies the error err of the file system, returning
+// ErrNotFound or an *Error wrapping err.
+//
+// efivarfs maps the status codes of the firmware to errno values:
+// EFI_WRITE_PROTECTED to EROFS, EFI_SECURITY_VIOLATION to EACCES,
+// EFI_INVALID_PARAMETER to EINVAL and EFI_OUT_OF_RESOURCES to ENOSPC.
+// Opening a variable for writing fails with EPERM if it is immutable
+// and with EROFS if efivarfs is mounted read-only.
+func (c FsContext) mapError(stage fsStage, err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, syscall.ENOENT):
+		if c.checkMount != nil {
+			if err := c.checkMount(); err != nil {
+				return err
+			}
+		}
+		return ErrNotFound
+	case errors.Is(err, syscall.ENOSPC):
+		return classify(ErrNoSpace, err)
+	case errors.Is(err, syscall.EINVAL) && stage == stageIO:
+		return classify(ErrInvalidAttributes, err)
+	case errors.Is(err, syscall.EROFS) && stage == stageIO:
+		return classify(ErrWriteProtected, err)
+	case errors.Is(err, syscall.EROFS):
+		return classify(ErrReadOnly, err)
+	case errors.Is(err, syscall.EACCES) && stage == stageIO:
+		return classify(ErrWriteProtected, err)
+	case errors.Is(err, syscall.EPERM) && stage == stageOpen:
+		return classify(ErrWriteProtected, err)
+	case errors.Is(err, fs.ErrPermission):
+		return classify(ErrPermission, err)
+	}
+	return err
+}
+
 func NewFileSystemContext(fs afero.Fs) *FsContext {
 	return &FsContext{fs: fs}
 }
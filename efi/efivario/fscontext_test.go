@@ -15,6 +15,7 @@
 package efivario
 
 import (
+	"bytes"
 	"errors"
 	"os"
 	"testing"
@@ -52,7 +53,7 @@ func (s *FsContextTestSuite) SetupTest() {
 	require.NoError(s.T(), err)
 
 	s.tmpDir = dir
-	s.context = &FsContext{afero.NewBasePathFs(afero.NewOsFs(), dir)}
+	s.context = &FsContext{fs: afero.NewBasePathFs(afero.NewOsFs(), dir)}
 }
 
 func (s *FsContextTestSuite) TearDownTest() {
@@ -116,6 +117,21 @@ func (s *FsContextTestSuite) TestGetTooShort() {
 	require.ErrorIs(s.T(), err, ErrInsufficientSpace)
 }
 
+// TestReadAllLarge tests reading a variable larger than a page.
+func (s *FsContextTestSuite) TestReadAllLarge() {
+	// given that ...
+	value := bytes.Repeat([]byte{0xaa}, 30<<10)
+	require.NoError(s.T(), s.context.Set("TestVar", testGuid, NonVolatile, value))
+
+	// when ...
+	attrs, got, err := ReadAll(s.context, "TestVar", testGuid)
+
+	// then ...
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), NonVolatile, attrs)
+	assert.Equal(s.T(), value, got)
+}
+
 // TestSetNewVariable tests setting a new variable.
 func (s *FsContextTestSuite) TestSetNewVariable() {
 	// given that ...
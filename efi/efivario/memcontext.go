@@ -0,0 +1,322 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivario
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf16"
+
+	"github.com/0x5a17ed/itkit"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+// authAttributes are the attributes requiring the value of a variable
+// to begin with an authentication descriptor.
+const authAttributes = AuthenticatedWriteAccess | TimeBasedAuthenticatedWriteAccess | EnhancedAuthenticatedAccess
+
+// knownAttributes are all attributes defined by the specification.
+const knownAttributes = NonVolatile | BootServiceAccess | RuntimeAccess | HardwareErrorRecord | authAttributes | AppendWrite
+
+const (
+	// efiTimeSize is the size of an EFI_TIME structure.
+	efiTimeSize = 16
+
+	// winCertificateUEFIGUIDSize is the size of the header of a
+	// WIN_CERTIFICATE_UEFI_GUID structure.
+	winCertificateUEFIGUIDSize = 24
+
+	// winCertTypeEFIGUID is the certificate type of a
+	// WIN_CERTIFICATE_UEFI_GUID structure.
+	winCertTypeEFIGUID = 0x0ef1
+
+	// auth3HeaderSize is the size of an
+	// EFI_VARIABLE_AUTHENTICATION_3 structure.
+	auth3HeaderSize = 10
+)
+
+var (
+	errEmptyName          = errors.New("empty variable name")
+	errUnknownAttributes  = errors.New("unknown attributes")
+	errRuntimeAccess      = errors.New("runtime access requires boot service access")
+	errDeprecatedAuth     = errors.New("authenticated write access is deprecated")
+	errConflictingAuth    = errors.New("conflicting authentication attributes")
+	errAttributesMismatch = errors.New("attributes differ from the existing variable")
+	errAuthDescriptor     = errors.New("missing or malformed authentication descriptor")
+	errQuotaExceeded      = errors.New("storage quota exceeded")
+)
+
+// memKey identifies a variable of MemContext.
+type memKey struct {
+	name string
+	guid efiguid.GUID
+}
+
+// size returns the storage size of a variable with the given value.
+func (k memKey) size(value []byte) int {
+	return 2*(len(utf16.Encode([]rune(k.name)))+1) + len(value)
+}
+
+// memVariable is a variable stored by MemContext.
+type memVariable struct {
+	attrs Attributes
+	value []byte
+}
+
+// memVarNameIterator is a variable name iterator for MemContext
+type memVarNameIterator struct {
+	items   []VariableNameItem
+	current *VariableNameItem
+}
+
+func (it *memVarNameIterator) Close() error {
+	return nil
+}
+
+func (it *memVarNameIterator) Iter() itkit.Iterator[VariableNameItem] {
+	return it
+}
+
+func (it *memVarNameIterator) Next() bool {
+	if len(it.items) == 0 {
+		it.current = nil
+		return false
+	}
+	it.current, it.items = &it.items[0], it.items[1:]
+	return true
+}
+
+func (it *memVarNameIterator) Value() VariableNameItem {
+	return *it.current
+}
+
+func (it *memVarNameIterator) Err() error {
+	return nil
+}
+
+// MemContext provides an in-memory implementation of the Context API
+// following the rules of the SetVariable() service, e.g. for testing
+// code using variables:
+//
+//   - writing an empty value without AppendWrite or writing without
+//     any attributes deletes the variable,
+//   - AppendWrite appends the value to the existing one,
+//   - the attributes of an existing variable can not be changed,
+//   - RuntimeAccess requires BootServiceAccess,
+//   - the value of authenticated variables must begin with an
+//     authentication descriptor, which is validated for its
+//     structure only and stripped from the stored value, and
+//   - exceeding the storage quota fails with ErrNoSpace.
+//
+// Section 8.2.3 "SetVariable()"
+type MemContext struct {
+	mu sync.Mutex
+
+	vars  map[memKey]*memVariable
+	quota int
+	used  int
+}
+
+// Ensure the public facing API in Context is implemented by MemContext.
+var _ Context = &MemContext{}
+
+// NewMemContext returns an empty MemContext storing up to quota bytes
+// of UTF-16 encoded variable names and values.  A quota of zero or
+// less disables the limit.
+func NewMemContext(quota int) *MemContext {
+	return &MemContext{vars: make(map[memKey]*memVariable), quota: quota}
+}
+
+func (c *MemContext) Close() error {
+	return nil
+}
+
+func (c *MemContext) VariableNames() (VariableNameIterator, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make([]VariableNameItem, 0, len(c.vars))
+	for k := range c.vars {
+		items = append(items, VariableNameItem{Name: k.name, GUID: k.guid})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return bytes.Compare(items[i].GUID[:], items[j].GUID[:]) < 0
+	})
+
+	return &memVarNameIterator{items: items}, nil
+}
+
+func (c *MemContext) GetSizeHint(name string, guid efiguid.GUID) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.vars[memKey{name, guid}]
+	if !ok {
+		return 0, fmt.Errorf("efivario/hint: %w", ErrNotFound)
+	}
+	return int64(len(v.value)), nil
+}
+
+// Get reads the variable into out.  If out is too small Get returns
+// the size of the value along with ErrInsufficientSpace, like the
+// GetVariable() service does.
+func (c *MemContext) Get(name string, guid efiguid.GUID, out []byte) (a Attributes, n int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.vars[memKey{name, guid}]
+	if !ok {
+		return 0, 0, fmt.Errorf("efivario/get: %w", ErrNotFound)
+	}
+	if len(out) < len(v.value) {
+		return v.attrs, len(v.value), fmt.Errorf("efivario/get: %w", ErrInsufficientSpace)
+	}
+	return v.attrs, copy(out, v.value), nil
+}
+
+func (c *MemContext) Set(name string, guid efiguid.GUID, attrs Attributes, value []byte) error {
+	if err := c.set(memKey{name, guid}, attrs, value); err != nil {
+		return fmt.Errorf("efivario/set: %w", err)
+	}
+	return nil
+}
+
+// Delete deletes the variable by writing it without attributes, which
+// fails with ErrWriteProtected for authenticated variables.
+func (c *MemContext) Delete(name string, guid efiguid.GUID) error {
+	if err := c.set(memKey{name, guid}, 0, nil); err != nil {
+		return fmt.Errorf("efivario/delete: %w", err)
+	}
+	return nil
+}
+
+func (c *MemContext) set(key memKey, attrs Attributes, value []byte) error {
+	if key.name == "" {
+		return classify(ErrInvalidAttributes, errEmptyName)
+	}
+	if err := checkAttributes(attrs); err != nil {
+		return classify(ErrInvalidAttributes, err)
+	}
+
+	if attrs&authAttributes != 0 {
+		n, err := authDescriptorSize(attrs, value)
+		if err != nil {
+			return classify(ErrWriteProtected, err)
+		}
+		value = value[n:]
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, exists := c.vars[key]
+
+	stored := attrs &^ AppendWrite
+	if exists && stored != 0 && stored != v.attrs {
+		return classify(ErrInvalidAttributes, errAttributesMismatch)
+	}
+
+	switch {
+	case attrs&AppendWrite != 0 && len(value) == 0 && stored != 0:
+		// Appending nothing leaves the variable as it is.
+		return nil
+
+	case stored == 0, len(value) == 0 && attrs&AppendWrite == 0:
+		if !exists {
+			return ErrNotFound
+		}
+		if v.attrs&authAttributes != 0 && attrs&authAttributes == 0 {
+			return classify(ErrWriteProtected, errAuthDescriptor)
+		}
+		c.used -= key.size(v.value)
+		delete(c.vars, key)
+		return nil
+	}
+
+	var oldSize int
+	newValue := append([]byte(nil), value...)
+	if exists {
+		oldSize = key.size(v.value)
+		if attrs&AppendWrite != 0 {
+			newValue = append(append([]byte(nil), v.value...), value...)
+		}
+	}
+
+	newSize := key.size(newValue)
+	if c.quota > 0 && c.used-oldSize+newSize > c.quota {
+		return classify(ErrNoSpace, errQuotaExceeded)
+	}
+
+	c.used += newSize - oldSize
+	c.vars[key] = &memVariable{attrs: stored, value: newValue}
+	return nil
+}
+
+// checkAttributes checks attrs for combinations rejected by the
+// SetVariable() service.
+func checkAttributes(attrs Attributes) error {
+	switch {
+	case attrs&^knownAttributes != 0:
+		return errUnknownAttributes
+	case attrs&RuntimeAccess != 0 && attrs&BootServiceAccess == 0:
+		return errRuntimeAccess
+	case attrs&AuthenticatedWriteAccess != 0:
+		return errDeprecatedAuth
+	case attrs&TimeBasedAuthenticatedWriteAccess != 0 && attrs&EnhancedAuthenticatedAccess != 0:
+		return errConflictingAuth
+	}
+	return nil
+}
+
+// authDescriptorSize returns the size of the authentication descriptor
+// value begins with.
+//
+// Section 8.2.3.2 "Using the EFI_VARIABLE_AUTHENTICATION_2 descriptor"
+// and 8.2.3.3 "Using the EFI_VARIABLE_AUTHENTICATION_3 descriptor"
+func authDescriptorSize(attrs Attributes, value []byte) (int, error) {
+	if attrs&EnhancedAuthenticatedAccess != 0 {
+		// EFI_VARIABLE_AUTHENTICATION_3 starts with its version,
+		// type and the size of all metadata preceding the data.
+		if len(value) < auth3HeaderSize || value[0] != 1 {
+			return 0, errAuthDescriptor
+		}
+		size := binary.LittleEndian.Uint32(value[2:])
+		if size < auth3HeaderSize || uint64(size) > uint64(len(value)) {
+			return 0, errAuthDescriptor
+		}
+		return int(size), nil
+	}
+
+	// EFI_VARIABLE_AUTHENTICATION_2 is an EFI_TIME followed by a
+	// WIN_CERTIFICATE_UEFI_GUID, whose length includes its header.
+	if len(value) < efiTimeSize+winCertificateUEFIGUIDSize {
+		return 0, errAuthDescriptor
+	}
+	cert := value[efiTimeSize:]
+	size := binary.LittleEndian.Uint32(cert)
+	if binary.LittleEndian.Uint16(cert[6:]) != winCertTypeEFIGUID ||
+		size < winCertificateUEFIGUIDSize || uint64(size) > uint64(len(cert)) {
+		return 0, errAuthDescriptor
+	}
+	return efiTimeSize + int(size), nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivario
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/0x5a17ed/itkit/iters/sliceit"
+	"github.com/0x5a17ed/itkit/itlib"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bootAttrs = NonVolatile | BootServiceAccess | RuntimeAccess
+
+// auth2Descriptor returns an EFI_VARIABLE_AUTHENTICATION_2 descriptor
+// carrying certData.
+func auth2Descriptor(certData []byte) []byte {
+	b := make([]byte, efiTimeSize+winCertificateUEFIGUIDSize, efiTimeSize+winCertificateUEFIGUIDSize+len(certData))
+	binary.LittleEndian.PutUint32(b[efiTimeSize:], uint32(winCertificateUEFIGUIDSize+len(certData)))
+	binary.LittleEndian.PutUint16(b[efiTimeSize+4:], 0x0200)
+	binary.LittleEndian.PutUint16(b[efiTimeSize+6:], winCertTypeEFIGUID)
+	return append(b, certData...)
+}
+
+func readMem(t *testing.T, c *MemContext, name string) (Attributes, []byte) {
+	attrs, value, err := ReadAll(c, name, testGuid)
+	require.NoError(t, err)
+	return attrs, value
+}
+
+func TestMemContext_Set(t *testing.T) {
+	c := NewMemContext(0)
+
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs, []byte{1, 2}))
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs|AppendWrite, []byte{3}))
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs|AppendWrite, nil))
+
+	attrs, value := readMem(t, c, "Test")
+	assert.Equal(t, bootAttrs, attrs)
+	assert.Equal(t, []byte{1, 2, 3}, value)
+
+	err := c.Set("Test", testGuid, NonVolatile|BootServiceAccess, []byte{4})
+	assert.ErrorIs(t, err, ErrInvalidAttributes)
+
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs, nil))
+	_, _, err = c.Get("Test", testGuid, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs|AppendWrite, []byte{5}))
+	require.NoError(t, c.Set("Test", testGuid, 0, []byte{6}))
+	assert.ErrorIs(t, c.Delete("Test", testGuid), ErrNotFound)
+}
+
+func TestMemContext_Attributes(t *testing.T) {
+	tt := []struct {
+		name    string
+		attrs   Attributes
+		value   []byte
+		wantErr error
+	}{
+		{"runtime without boot service", NonVolatile | RuntimeAccess, []byte{1}, ErrInvalidAttributes},
+		{"unknown", bootAttrs | 0x100, []byte{1}, ErrInvalidAttributes},
+		{"deprecated authentication", bootAttrs | AuthenticatedWriteAccess, []byte{1}, ErrInvalidAttributes},
+		{"missing descriptor", bootAttrs | TimeBasedAuthenticatedWriteAccess, []byte{1}, ErrWriteProtected},
+		{"short descriptor", bootAttrs | TimeBasedAuthenticatedWriteAccess, auth2Descriptor(nil)[:30], ErrWriteProtected},
+		{"enhanced descriptor", bootAttrs | EnhancedAuthenticatedAccess, []byte{2, 0, 10, 0, 0, 0, 0, 0, 0, 0, 1}, ErrWriteProtected},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewMemContext(0)
+			assert.ErrorIs(t, c.Set("Test", testGuid, tc.attrs, tc.value), tc.wantErr)
+		})
+	}
+}
+
+func TestMemContext_Authenticated(t *testing.T) {
+	const attrs = bootAttrs | TimeBasedAuthenticatedWriteAccess
+
+	c := NewMemContext(0)
+	require.NoError(t, c.Set("db", testGuid, attrs, append(auth2Descriptor([]byte{0xcc}), 1, 2)))
+	require.NoError(t, c.Set("db", testGuid, attrs|AppendWrite, append(auth2Descriptor(nil), 3)))
+
+	got, value := readMem(t, c, "db")
+	assert.Equal(t, attrs, got)
+	assert.Equal(t, []byte{1, 2, 3}, value)
+
+	assert.ErrorIs(t, c.Delete("db", testGuid), ErrWriteProtected)
+	require.NoError(t, c.Set("db", testGuid, attrs, auth2Descriptor(nil)))
+	_, err := c.GetSizeHint("db", testGuid)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, c.Set("dbx", testGuid, bootAttrs|EnhancedAuthenticatedAccess, []byte{1, 0, 11, 0, 0, 0, 0, 0, 0, 0, 0xcc, 4}))
+	_, value = readMem(t, c, "dbx")
+	assert.Equal(t, []byte{4}, value)
+}
+
+func TestMemContext_Quota(t *testing.T) {
+	// "Test" takes 10 bytes of storage.
+	c := NewMemContext(16)
+
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs, make([]byte, 6)))
+	assert.ErrorIs(t, c.Set("Test", testGuid, bootAttrs|AppendWrite, []byte{1}), ErrNoSpace)
+	assert.ErrorIs(t, c.Set("Tset", testGuid, bootAttrs, []byte{1}), ErrNoSpace)
+
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs, make([]byte, 2)))
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs|AppendWrite, make([]byte, 4)))
+	require.NoError(t, c.Delete("Test", testGuid))
+	require.NoError(t, c.Set("Tset", testGuid, bootAttrs, make([]byte, 6)))
+}
+
+func TestMemContext_Get(t *testing.T) {
+	c := NewMemContext(0)
+	require.NoError(t, c.Set("Test", testGuid, bootAttrs, []byte{1, 2, 3}))
+
+	attrs, n, err := c.Get("Test", testGuid, make([]byte, 2))
+	assert.ErrorIs(t, err, ErrInsufficientSpace)
+	assert.Equal(t, bootAttrs, attrs)
+	assert.Equal(t, 3, n)
+
+	value := []byte{9}
+	require.NoError(t, c.Set("Empty", testGuid, bootAttrs|AppendWrite, value))
+	value[0] = 0
+	_, got := readMem(t, c, "Empty")
+	assert.Equal(t, []byte{9}, got)
+}
+
+func TestMemContext_VariableNames(t *testing.T) {
+	c := NewMemContext(0)
+	for _, name := range []string{"Charlie", "Alice", "Bob"} {
+		require.NoError(t, c.Set(name, testGuid, bootAttrs, []byte{1}))
+	}
+
+	iter, err := c.VariableNames()
+	require.NoError(t, err)
+	defer iter.Close()
+
+	s := sliceit.To(itlib.Map(iter.Iter(), func(t VariableNameItem) string { return t.Name }))
+	assert.Equal(t, []string{"Alice", "Bob", "Charlie"}, s)
+	assert.NoError(t, iter.Err())
+}
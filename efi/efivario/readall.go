@@ -16,33 +16,90 @@ package efivario
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/0x5a17ed/uefi/efi/efiguid"
 )
 
-func ReadAll(c Context, name string, guid efiguid.GUID) (
-	attrs Attributes,
-	out []byte,
-	err error,
-) {
-	hint, err := c.GetSizeHint(name, guid)
-	if err != nil || hint < 0 {
-		hint = 8
+// DefaultMaxVariableSize is the size limit of variables read by
+// ReadAll.  It is well above the size of the largest variables found
+// in practice, like the db and dbx signature databases.
+const DefaultMaxVariableSize = 4 << 20
+
+// initialReadSize is the buffer size ReadAll starts with if the
+// context provides no size hint.
+const initialReadSize = 512
+
+var ErrTooLarge = errors.New("variable exceeds size limit")
+
+// SizeError is returned by ReadAll for variables exceeding the size
+// limit.  It matches ErrTooLarge using errors.Is.
+type SizeError struct {
+	Name string
+	GUID efiguid.GUID
+
+	// Limit is the size limit in bytes.
+	Limit int
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("efivario: %s-%s: %v (%d bytes)", e.Name, e.GUID, ErrTooLarge, e.Limit)
+}
+
+func (e *SizeError) Unwrap() error {
+	return ErrTooLarge
+}
+
+// ReadAll reads the whole value of the variable, limiting it to
+// DefaultMaxVariableSize bytes.
+func ReadAll(c Context, name string, guid efiguid.GUID) (Attributes, []byte, error) {
+	return ReadAllLimit(c, name, guid, DefaultMaxVariableSize)
+}
+
+// ReadAllLimit reads the whole value of the variable and fails with
+// a *SizeError if it exceeds limit bytes.
+//
+// The value is read into a buffer sized after GetSizeHint, which is
+// grown as long as the context reports ErrInsufficientSpace, e.g.
+// because the variable has grown in the meantime.
+func ReadAllLimit(c Context, name string, guid efiguid.GUID, limit int) (attrs Attributes, out []byte, err error) {
+	size := initialReadSize
+	if hint, err := c.GetSizeHint(name, guid); err == nil && hint >= 0 {
+		if hint > int64(limit) {
+			return 0, nil, &SizeError{Name: name, GUID: guid, Limit: limit}
+		}
+		size = int(hint)
 	}
 
-	out = make([]byte, hint)
-	for i := int(hint) << 1; i <= 4096; i = i << 1 {
+	for {
+		if size > limit {
+			size = limit
+		}
+
+		// The contents of a buffer which was too small are of no
+		// use, so a new buffer is allocated instead of growing it.
+		out = make([]byte, size)
+
 		var n int
 		attrs, n, err = c.Get(name, guid, out)
-		if err != nil {
-			if errors.Is(err, ErrInsufficientSpace) {
-				out = append(make([]byte, i-len(out)), out...)
-				continue
-			}
-			return
+		switch {
+		case err == nil && n > len(out):
+			return 0, nil, fmt.Errorf("efivario: %s-%s: %w", name, guid, ErrInsufficientSpace)
+		case err == nil:
+			return attrs, out[:n], nil
+		case !errors.Is(err, ErrInsufficientSpace):
+			return 0, nil, err
+		case size >= limit:
+			return 0, nil, &SizeError{Name: name, GUID: guid, Limit: limit}
+		}
+
+		grown := size * 2
+		if grown < initialReadSize {
+			grown = initialReadSize
+		}
+		if hint, err := c.GetSizeHint(name, guid); err == nil && hint > int64(size) {
+			grown = int(hint)
 		}
-		out = out[:n]
-		return
+		size = grown
 	}
-	return
 }
@@ -0,0 +1,95 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efivario
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+)
+
+// readAllContext is a Context holding a single variable for testing
+// ReadAll.
+type readAllContext struct {
+	Context
+
+	value []byte
+	hint  int64
+	gets  []int
+}
+
+func (c *readAllContext) GetSizeHint(string, efiguid.GUID) (int64, error) {
+	if c.hint < 0 {
+		return 0, errors.New("no hint")
+	}
+	return c.hint, nil
+}
+
+func (c *readAllContext) Get(_ string, _ efiguid.GUID, out []byte) (Attributes, int, error) {
+	c.gets = append(c.gets, len(out))
+	if c.value == nil {
+		return 0, 0, ErrNotFound
+	}
+	if len(out) < len(c.value) {
+		return 0, 0, ErrInsufficientSpace
+	}
+	return NonVolatile, copy(out, c.value), nil
+}
+
+func TestReadAll(t *testing.T) {
+	large := bytes.Repeat([]byte{0xaa}, 30<<10)
+
+	tt := []struct {
+		name     string
+		ctx      *readAllContext
+		limit    int
+		want     []byte
+		wantErr  error
+		wantGets []int
+	}{
+		{"exact hint", &readAllContext{value: large, hint: int64(len(large))}, DefaultMaxVariableSize, large, nil, []int{len(large)}},
+		{"no hint", &readAllContext{value: large, hint: -1}, DefaultMaxVariableSize, large, nil, []int{512, 1 << 10, 2 << 10, 4 << 10, 8 << 10, 16 << 10, 32 << 10}},
+		{"stale hint", &readAllContext{value: large, hint: 16}, DefaultMaxVariableSize, large, nil, []int{16, 512, 1 << 10, 2 << 10, 4 << 10, 8 << 10, 16 << 10, 32 << 10}},
+		{"hint exceeds limit", &readAllContext{value: large, hint: int64(len(large))}, 1 << 10, nil, ErrTooLarge, nil},
+		{"value exceeds limit", &readAllContext{value: large, hint: -1}, 1 << 10, nil, ErrTooLarge, []int{512, 1 << 10}},
+		{"value at limit", &readAllContext{value: large[:1<<10], hint: -1}, 1 << 10, large[:1<<10], nil, []int{512, 1 << 10}},
+		{"zero hint", &readAllContext{value: large[:10], hint: 0}, DefaultMaxVariableSize, large[:10], nil, []int{0, 512}},
+		{"empty", &readAllContext{value: []byte{}, hint: 0}, DefaultMaxVariableSize, []byte{}, nil, []int{0}},
+		{"not found", &readAllContext{hint: -1}, DefaultMaxVariableSize, nil, ErrNotFound, []int{512}},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			attrs, got, err := ReadAllLimit(tc.ctx, "TestVar", testGuid, tc.limit)
+			require.ErrorIs(t, err, tc.wantErr)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantGets, tc.ctx.gets)
+			if err == nil {
+				assert.Equal(t, NonVolatile, attrs)
+			}
+		})
+	}
+}
+
+func TestSizeError(t *testing.T) {
+	err := error(&SizeError{Name: "db", GUID: testGuid, Limit: 10})
+	assert.ErrorIs(t, err, ErrTooLarge)
+	assert.EqualError(t, err, "efivario: db-3CD99F3F-4B2B-43EB-AC29-F0890A4772B7: variable exceeds size limit (10 bytes)")
+}
@@ -18,6 +18,7 @@ package efivario
 
 import (
 	"errors"
+	"io"
 	"os"
 	"syscall"
 
@@ -45,18 +46,72 @@ func setFlags(fd uintptr, attr flags) error {
 	return unix.IoctlSetPointerInt(int(fd), unix.FS_IOC_SETFLAGS, int(attr))
 }
 
-func resolveOsFile(f afero.File) (o *os.File, ok bool) {
+// flagsFile is implemented by files providing their inode flags
+// without a file descriptor, like the files of efivarfstest.Fs.
+type flagsFile interface {
+	GetFlags() (uint32, error)
+	SetFlags(flags uint32) error
+}
+
+// inodeFlags provides access to the inode flags of a file.
+type inodeFlags interface {
+	io.Closer
+	get() (flags, error)
+	set(fl flags) error
+}
+
+// osInodeFlags accesses the inode flags of an *os.File using ioctl.
+type osInodeFlags struct{ *os.File }
+
+func (o osInodeFlags) get() (fl flags, err error) {
+	err = withInnerFileDescriptor(o.File, func(fd uintptr) (err error) {
+		fl, err = getFlags(fd)
+		return
+	})
+	return
+}
+
+func (o osInodeFlags) set(fl flags) error {
+	return withInnerFileDescriptor(o.File, func(fd uintptr) error {
+		return setFlags(fd, fl)
+	})
+}
+
+// fileInodeFlags accesses the inode flags of a flagsFile.
+type fileInodeFlags struct {
+	io.Closer
+	f flagsFile
+}
+
+func (o fileInodeFlags) get() (flags, error) {
+	fl, err := o.f.GetFlags()
+	return flags(fl), err
+}
+
+func (o fileInodeFlags) set(fl flags) error {
+	return o.f.SetFlags(uint32(fl))
+}
+
+// resolveInodeFlags returns the means to access the inode flags of f.
+func resolveInodeFlags(f afero.File) (o inodeFlags, ok bool) {
+	inner := f
+
 	// Unwrap afero.BasePathFile instances.
 	for {
-		if baseFile, ok := f.(*afero.BasePathFile); ok {
-			f = baseFile.File
+		if baseFile, ok := inner.(*afero.BasePathFile); ok {
+			inner = baseFile.File
 			continue
 		}
 		break
 	}
 
-	o, ok = f.(*os.File)
-	return
+	switch v := inner.(type) {
+	case *os.File:
+		return osInodeFlags{v}, true
+	case flagsFile:
+		return fileInodeFlags{Closer: f, f: v}, true
+	}
+	return nil, false
 }
 
 func withInnerFileDescriptor(f *os.File, cb func(fd uintptr) error) (err error) {
@@ -77,29 +132,25 @@ func withInnerFileDescriptor(f *os.File, cb func(fd uintptr) error) (err error)
 }
 
 type safeguard struct {
-	*os.File
+	inodeFlags
 	fl flags
 }
 
 func (g *safeguard) disable() (wasProtected bool, err error) {
 	if g != nil {
-		err = withInnerFileDescriptor(g.File, func(fd uintptr) (err error) {
-			wasProtected = g.fl.IsSet(FS_IMMUTABLE_FL)
-			if !wasProtected {
-				return nil
-			}
-			g.fl = g.fl.Clear(FS_IMMUTABLE_FL)
-			return setFlags(fd, g.fl)
-		})
+		wasProtected = g.fl.IsSet(FS_IMMUTABLE_FL)
+		if !wasProtected {
+			return
+		}
+		g.fl = g.fl.Clear(FS_IMMUTABLE_FL)
+		err = g.set(g.fl)
 	}
 	return
 }
 
 func (g *safeguard) enable() error {
-	return withInnerFileDescriptor(g.File, func(fd uintptr) error {
-		g.fl = g.fl.Set(FS_IMMUTABLE_FL)
-		return setFlags(fd, g.fl)
-	})
+	g.fl = g.fl.Set(FS_IMMUTABLE_FL)
+	return g.set(g.fl)
 }
 
 func openSafeguard(fs afero.Fs, fpath string) (p *safeguard, err error) {
@@ -115,17 +166,14 @@ func openSafeguard(fs afero.Fs, fpath string) (p *safeguard, err error) {
 		}
 	}
 
-	osFile, ok := resolveOsFile(f)
+	inode, ok := resolveInodeFlags(f)
 	if !ok {
 		// The protection operation is not implemented by the
 		// underlying filesystem and thus can't be performed.
 		return nil, f.Close()
 	}
 
-	p = &safeguard{File: osFile}
-	err = withInnerFileDescriptor(osFile, func(fd uintptr) (err error) {
-		p.fl, err = getFlags(fd)
-		return
-	})
+	p = &safeguard{inodeFlags: inode}
+	p.fl, err = inode.get()
 	return
 }
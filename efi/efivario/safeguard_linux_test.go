@@ -0,0 +1,113 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package efivario
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efivario/efivarfstest"
+)
+
+var globalGuid = efiguid.MustFromString("8be4df61-93ca-11d2-aa0d-00e098032b8c")
+
+const efivarfsAttrs = NonVolatile | BootServiceAccess | RuntimeAccess
+
+func isImmutable(t *testing.T, fs afero.Fs, name string) bool {
+	f, err := fs.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	inode, ok := resolveInodeFlags(f)
+	require.True(t, ok)
+
+	fl, err := inode.get()
+	require.NoError(t, err)
+	return fl.IsSet(FS_IMMUTABLE_FL)
+}
+
+func TestFsContext_Safeguard(t *testing.T) {
+	fs := efivarfstest.New()
+	c := NewFileSystemContext(afero.NewBasePathFs(fs, "/"))
+
+	name := getFileName("BootNext", globalGuid)
+	require.NoError(t, fs.SetVariable(name, uint32(efivarfsAttrs), []byte{1, 0}))
+	require.True(t, isImmutable(t, fs, name))
+
+	require.NoError(t, c.Set("BootNext", globalGuid, efivarfsAttrs, []byte{2, 0}))
+	assert.True(t, isImmutable(t, fs, name))
+
+	out := make([]byte, 2)
+	_, _, err := c.Get("BootNext", globalGuid, out)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2, 0}, out)
+
+	require.NoError(t, c.Delete("BootNext", globalGuid))
+	_, _, err = c.Get("BootNext", globalGuid, out)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// efivarfs makes new variables of the specification immutable.
+	require.NoError(t, c.Set("BootNext", globalGuid, efivarfsAttrs, []byte{3, 0}))
+	assert.True(t, isImmutable(t, fs, name))
+}
+
+func TestFsContext_Efivarfs(t *testing.T) {
+	fs := efivarfstest.New()
+	c := NewFileSystemContext(fs)
+
+	require.NoError(t, fs.SetVariable("Test-"+strings.ToUpper(testGuid.String()), uint32(efivarfsAttrs), []byte{1}))
+
+	hint, err := c.GetSizeHint("Test", testGuid)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), hint)
+
+	attrs, value, err := ReadAll(c, "Test", testGuid)
+	require.NoError(t, err)
+	assert.Equal(t, efivarfsAttrs, attrs)
+	assert.Equal(t, []byte{1}, value)
+
+	require.NoError(t, c.Set("Test", testGuid, efivarfsAttrs|AppendWrite, []byte{2}))
+	_, value, err = ReadAll(c, "Test", testGuid)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, value)
+
+	err = c.Set("Test", testGuid, NonVolatile|BootServiceAccess, []byte{3})
+	assert.ErrorIs(t, err, ErrInvalidAttributes)
+
+	err = c.Set("Test", testGuid, 0x100, []byte{3})
+	assert.ErrorIs(t, err, ErrInvalidAttributes)
+
+	require.NoError(t, fs.SetImmutable("Test-"+testGuid.String(), true))
+	require.NoError(t, c.Set("Test", testGuid, efivarfsAttrs, []byte{3}))
+
+	// Writing an empty value deletes the variable.
+	require.NoError(t, c.Set("Test", testGuid, efivarfsAttrs, nil))
+	_, err = c.GetSizeHint("Test", testGuid)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, c.Delete("Test", testGuid), ErrNotFound)
+
+	iter, err := c.VariableNames()
+	require.NoError(t, err)
+	defer iter.Close()
+	assert.False(t, iter.Next())
+	assert.NoError(t, iter.Err())
+}
@@ -40,25 +40,25 @@ var (
 	// BootNext specifies the first boot option on the next boot.
 	//
 	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
-	BootNext = Variable[uint16]{
-		name:         BootNextName,
-		guid:         GlobalVariable,
-		defaultAttrs: defaultAttrs,
-		marshal:      primitiveMarshaller[uint16],
-		unmarshal:    primitiveUnmarshaller[uint16],
-	}
+	BootNext = NewVariable[uint16](
+		BootNextName,
+		GlobalVariable,
+		defaultAttrs,
+		PrimitiveMarshaller[uint16],
+		PrimitiveUnmarshaller[uint16],
+	)
 
 	// BootCurrent defines the Boot#### option that was selected
 	// on the current boot.
 	//
 	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
-	BootCurrent = Variable[uint16]{
-		name:         BootCurrentName,
-		guid:         GlobalVariable,
-		defaultAttrs: defaultAttrs,
-		marshal:      primitiveMarshaller[uint16],
-		unmarshal:    primitiveUnmarshaller[uint16],
-	}
+	BootCurrent = NewVariable[uint16](
+		BootCurrentName,
+		GlobalVariable,
+		defaultAttrs,
+		PrimitiveMarshaller[uint16],
+		PrimitiveUnmarshaller[uint16],
+	)
 
 	// BootOrder is an ordered list of the Boot#### options.
 	//
@@ -69,13 +69,13 @@ var (
 	// boot order.
 	//
 	// <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
-	BootOrder = Variable[[]uint16]{
-		name:         BootOrderName,
-		guid:         GlobalVariable,
-		defaultAttrs: defaultAttrs,
-		unmarshal:    sliceUnmarshaller[uint16],
-		marshal:      sliceMarshaller[uint16],
-	}
+	BootOrder = NewVariable[[]uint16](
+		BootOrderName,
+		GlobalVariable,
+		defaultAttrs,
+		SliceMarshaller[uint16],
+		SliceUnmarshaller[uint16],
+	)
 )
 
 // Boot returns an EFI Variable pointing to the boot LoadOption
@@ -83,11 +83,13 @@ var (
 //
 // <https://uefi.org/sites/default/files/resources/UEFI_Spec_2_9_2021_03_18.pdf#G7.1346720>
 func Boot(i uint16) Variable[*efitypes.LoadOption] {
-	return Variable[*efitypes.LoadOption]{
-		name:      fmt.Sprintf("Boot%04X", i),
-		guid:      GlobalVariable,
-		unmarshal: structUnmarshaller[efitypes.LoadOption],
-	}
+	return NewVariable[*efitypes.LoadOption](
+		fmt.Sprintf("Boot%04X", i),
+		GlobalVariable,
+		defaultAttrs,
+		StructMarshaller[*efitypes.LoadOption],
+		StructUnmarshaller[efitypes.LoadOption],
+	)
 }
 
 // BootEntry describes a Boot efitypes.LoadOption value.
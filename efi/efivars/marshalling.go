@@ -20,18 +20,28 @@ import (
 	"errors"
 	"fmt"
 	"io"
+
+	"github.com/0x5a17ed/uefi/efi/eficodec"
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
 )
 
-func primitiveUnmarshaller[T any](r io.Reader) (out T, err error) {
+// PrimitiveUnmarshaller decodes a fixed-size value, like an integer
+// or an array of integers, in little endian byte order.
+func PrimitiveUnmarshaller[T any](r io.Reader) (out T, err error) {
 	err = binary.Read(r, binary.LittleEndian, &out)
 	return
 }
 
-func primitiveMarshaller[T any](w io.Writer, inp T) error {
+// PrimitiveMarshaller encodes a fixed-size value, like an integer or
+// an array of integers, in little endian byte order.
+func PrimitiveMarshaller[T any](w io.Writer, inp T) error {
 	return binary.Write(w, binary.LittleEndian, inp)
 }
 
-func sliceUnmarshaller[T any](r io.Reader) (out []T, err error) {
+// SliceUnmarshaller decodes a list of fixed-size values filling the
+// whole variable, like BootOrder.
+func SliceUnmarshaller[T any](r io.Reader) (out []T, err error) {
 	for i := 0; ; i += 1 {
 		var item T
 		err = binary.Read(r, binary.LittleEndian, &item)
@@ -47,7 +57,8 @@ func sliceUnmarshaller[T any](r io.Reader) (out []T, err error) {
 	}
 }
 
-func sliceMarshaller[T any](w io.Writer, inp []T) (err error) {
+// SliceMarshaller encodes a list of fixed-size values.
+func SliceMarshaller[T any](w io.Writer, inp []T) (err error) {
 	var buf bytes.Buffer
 
 	for i, item := range inp {
@@ -61,12 +72,46 @@ func sliceMarshaller[T any](w io.Writer, inp []T) (err error) {
 	return
 }
 
+// GUIDUnmarshaller decodes a single GUID.
+func GUIDUnmarshaller(r io.Reader) (out efiguid.GUID, err error) {
+	_, err = io.ReadFull(r, out[:])
+	return
+}
+
+// GUIDMarshaller encodes a single GUID.
+func GUIDMarshaller(w io.Writer, inp efiguid.GUID) error {
+	_, err := w.Write(inp[:])
+	return err
+}
+
+// UTF16StringUnmarshaller decodes a null terminated UTF-16 string
+// filling the whole variable.
+func UTF16StringUnmarshaller(r io.Reader) (string, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return efireader.DecodeUTF16Z(b, efireader.UTF16)
+}
+
+// UTF16StringMarshaller encodes a null terminated UTF-16 string.
+func UTF16StringMarshaller(w io.Writer, inp string) error {
+	b, err := efireader.EncodeUTF16Z(inp, efireader.UTF16)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
 type readerFrom[T any] interface {
 	io.ReaderFrom
 	*T
 }
 
-func structUnmarshaller[T any, PT readerFrom[T]](r io.Reader) (out *T, err error) {
+// StructUnmarshaller decodes a value of a type implementing
+// io.ReaderFrom through its pointer, like efitypes.LoadOption.
+func StructUnmarshaller[T any, PT readerFrom[T]](r io.Reader) (out *T, err error) {
 	var value T
 	_, err = PT(&value).ReadFrom(r)
 	if err == nil {
@@ -75,7 +120,25 @@ func structUnmarshaller[T any, PT readerFrom[T]](r io.Reader) (out *T, err error
 	return
 }
 
-func structMarshaller[T io.WriterTo](w io.Writer, inp T) (err error) {
+// StructMarshaller encodes a value implementing io.WriterTo.
+func StructMarshaller[T io.WriterTo](w io.Writer, inp T) (err error) {
 	_, err = inp.WriteTo(w)
 	return
 }
+
+// CodecUnmarshaller decodes a struct declared with efi struct tags,
+// see eficodec.
+func CodecUnmarshaller[T any](r io.Reader) (out *T, err error) {
+	var value T
+	if _, err = eficodec.ReadFrom(r, &value); err == nil {
+		out = &value
+	}
+	return
+}
+
+// CodecMarshaller encodes a struct declared with efi struct tags,
+// see eficodec.
+func CodecMarshaller[T any](w io.Writer, inp *T) (err error) {
+	_, err = eficodec.WriteTo(w, inp)
+	return
+}
@@ -16,6 +16,7 @@ package efivars
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 
@@ -29,9 +30,18 @@ const (
 	defaultAttrs = efivario.NonVolatile | globalAccess
 )
 
+var (
+	ErrUnsupported = errors.New("operation not supported by variable")
+)
+
+// MarshalFn encodes the value of a variable to w.
 type MarshalFn[T any] func(w io.Writer, inp T) error
+
+// UnmarshalFn decodes the value of a variable from r, which holds
+// the whole contents of the variable.
 type UnmarshalFn[T any] func(r io.Reader) (T, error)
 
+// Variable describes an EFI variable holding a value of type T.
 type Variable[T any] struct {
 	name         string
 	guid         efiguid.GUID
@@ -41,37 +51,121 @@ type Variable[T any] struct {
 	unmarshal UnmarshalFn[T]
 }
 
+// NewVariable returns a Variable with the given name and vendor GUID
+// whose value is encoded with marshal and decoded with unmarshal.
+// Either of them may be nil for variables which can only be read or
+// written.  attrs are the attributes used by Set.
+//
+//	var LoaderEntrySelected = efivars.NewVariable(
+//		"LoaderEntrySelected",
+//		loaderGUID,
+//		efivario.BootServiceAccess|efivario.RuntimeAccess,
+//		efivars.UTF16StringMarshaller,
+//		efivars.UTF16StringUnmarshaller,
+//	)
+func NewVariable[T any](
+	name string,
+	guid efiguid.GUID,
+	attrs efivario.Attributes,
+	marshal MarshalFn[T],
+	unmarshal UnmarshalFn[T],
+) Variable[T] {
+	return Variable[T]{
+		name:         name,
+		guid:         guid,
+		defaultAttrs: attrs,
+		marshal:      marshal,
+		unmarshal:    unmarshal,
+	}
+}
+
+// Name returns the name of the variable.
+func (e Variable[T]) Name() string {
+	return e.name
+}
+
+// GUID returns the vendor GUID of the variable.
+func (e Variable[T]) GUID() efiguid.GUID {
+	return e.guid
+}
+
+// DefaultAttributes returns the attributes the variable is written
+// with by Set.
+func (e Variable[T]) DefaultAttributes() efivario.Attributes {
+	return e.defaultAttrs
+}
+
+// errorf returns an error for the operation op on the variable,
+// identified by its name and vendor GUID.
+func (e Variable[T]) errorf(op string, format string, args ...any) error {
+	return fmt.Errorf("efivars/%s(%s-%s): "+format, append([]any{op, e.name, e.guid}, args...)...)
+}
+
 func (e Variable[T]) Get(c efivario.Context) (attrs efivario.Attributes, value T, err error) {
 	if e.unmarshal == nil {
-		err = fmt.Errorf("efivars/get(%s): unsupported", e.name)
+		err = e.errorf("get", "%w", ErrUnsupported)
 		return
 	}
 
 	attrs, data, err := efivario.ReadAll(c, e.name, e.guid)
 	if err != nil {
-		err = fmt.Errorf("efivars/get(%s): load: %w", e.name, err)
+		err = e.errorf("get", "load: %w", err)
 		return
 	}
 
 	value, err = e.unmarshal(bytes.NewReader(data))
 	if err != nil {
-		err = fmt.Errorf("efivars/get(%s): parse: %w", e.name, err)
+		err = e.errorf("get", "parse: %w", err)
 	}
 	return
 }
 
+// GetOrDefault returns the value of the variable, or def if the
+// variable does not exist.
+func (e Variable[T]) GetOrDefault(c efivario.Context, def T) (T, error) {
+	_, value, err := e.Get(c)
+	if errors.Is(err, efivario.ErrNotFound) {
+		return def, nil
+	}
+	return value, err
+}
+
+// Exists reports whether the variable exists.
+func (e Variable[T]) Exists(c efivario.Context) (bool, error) {
+	_, _, err := c.Get(e.name, e.guid, nil)
+	switch {
+	case err == nil, errors.Is(err, efivario.ErrInsufficientSpace):
+		return true, nil
+	case errors.Is(err, efivario.ErrNotFound):
+		return false, nil
+	}
+	return false, e.errorf("exists", "%w", err)
+}
+
 func (e Variable[T]) SetWithAttributes(c efivario.Context, attrs efivario.Attributes, value T) error {
 	if e.marshal == nil {
-		return fmt.Errorf("efivars/set(%s): unsupported", e.name)
+		return e.errorf("set", "%w", ErrUnsupported)
 	}
 
 	var buf bytes.Buffer
 	if err := e.marshal(&buf, value); err != nil {
-		return fmt.Errorf("efivars/set(%s): write: %w", e.name, err)
+		return e.errorf("set", "write: %w", err)
+	}
+	if err := c.Set(e.name, e.guid, attrs, buf.Bytes()); err != nil {
+		return e.errorf("set", "store: %w", err)
 	}
-	return c.Set(e.name, e.guid, attrs, buf.Bytes())
+	return nil
 }
 
 func (e Variable[T]) Set(c efivario.Context, value T) error {
 	return e.SetWithAttributes(c, e.defaultAttrs, value)
 }
+
+// Delete removes the variable.  Deleting a variable which does not
+// exist fails with efivario.ErrNotFound.
+func (e Variable[T]) Delete(c efivario.Context) error {
+	if err := c.Delete(e.name, e.guid); err != nil {
+		return e.errorf("delete", "%w", err)
+	}
+	return nil
+}
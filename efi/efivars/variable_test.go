@@ -29,6 +29,9 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+	"github.com/0x5a17ed/uefi/efi/efitypes"
+	"github.com/0x5a17ed/uefi/efi/efitypes/efidevicepath"
 	"github.com/0x5a17ed/uefi/efi/efivario"
 )
 
@@ -145,8 +148,8 @@ func (s *VariableTestSuite) TestPrimitive() {
 			name:         "TestVar",
 			guid:         testGuid,
 			defaultAttrs: defaultAttrs,
-			unmarshal:    primitiveUnmarshaller[uint16],
-			marshal:      primitiveMarshaller[uint16],
+			unmarshal:    PrimitiveUnmarshaller[uint16],
+			marshal:      PrimitiveMarshaller[uint16],
 		}
 
 		row := &testRow[uint16]{
@@ -165,8 +168,8 @@ func (s *VariableTestSuite) TestPrimitive() {
 			name:         "TestVar",
 			guid:         testGuid,
 			defaultAttrs: defaultAttrs,
-			unmarshal:    primitiveUnmarshaller[bool],
-			marshal:      primitiveMarshaller[bool],
+			unmarshal:    PrimitiveUnmarshaller[bool],
+			marshal:      PrimitiveMarshaller[bool],
 		}
 
 		row := &testRow[bool]{
@@ -186,8 +189,8 @@ func (s *VariableTestSuite) TestSlice() {
 		name:         "TestVar",
 		guid:         testGuid,
 		defaultAttrs: defaultAttrs,
-		marshal:      sliceMarshaller[uint16],
-		unmarshal:    sliceUnmarshaller[uint16],
+		marshal:      SliceMarshaller[uint16],
+		unmarshal:    SliceUnmarshaller[uint16],
 	}
 
 	s.Run("SetGet", func() {
@@ -238,8 +241,8 @@ func (s *VariableTestSuite) TestGetStruct() {
 		name:         "TestVar",
 		guid:         testGuid,
 		defaultAttrs: defaultAttrs,
-		marshal:      structMarshaller[*data],
-		unmarshal:    structUnmarshaller[data],
+		marshal:      StructMarshaller[*data],
+		unmarshal:    StructUnmarshaller[data],
 	}
 
 	s.Run("SetGet", func() {
@@ -264,6 +267,148 @@ func (s *VariableTestSuite) TestGetStruct() {
 	})
 }
 
+func (s *VariableTestSuite) TestBootOption() {
+	env := newTestEnv[*efitypes.LoadOption](s.T())
+
+	v := Boot(0x1a)
+	lopt, err := efitypes.NewLoadOption(
+		"Test",
+		efitypes.ActiveAttribute,
+		efidevicepath.DevicePaths{
+			&efidevicepath.FilePathDevicePath{PathName: []byte{'\\', 0x00, 'a', 0x00, 0x00, 0x00}},
+		},
+		[]byte{0x01, 0x02},
+	)
+	s.Require().NoError(err)
+
+	s.Require().NoError(v.Set(env.ctx, lopt))
+
+	content, err := readFile(env.fs, "Boot001A-"+GlobalVariable.String())
+	s.Require().NoError(err)
+	s.Equal("07000000"+"01000000"+"0e00"+"5400650073007400"+"0000"+
+		"04040a005c0061000000"+"7fff0400"+"0102", content)
+
+	attrs, got, err := v.Get(env.ctx)
+	s.Require().NoError(err)
+	s.Equal(defaultAttrs, attrs)
+	s.Equal("Test", got.DescriptionString())
+	s.Equal([]string{`File(\a)`}, got.FilePathList.AllText())
+	s.Equal([]byte{0x01, 0x02}, got.OptionalData)
+}
+
+func (s *VariableTestSuite) TestNewVariable() {
+	v := NewVariable("TestVar", testGuid, globalAccess, GUIDMarshaller, GUIDUnmarshaller)
+	s.Equal("TestVar", v.Name())
+	s.Equal(testGuid, v.GUID())
+	s.Equal(globalAccess, v.DefaultAttributes())
+
+	env := newTestEnv[efiguid.GUID](s.T())
+	s.Require().NoError(v.Set(env.ctx, GlobalVariable))
+
+	attrs, got, err := v.Get(env.ctx)
+	s.Require().NoError(err)
+	s.Equal(globalAccess, attrs)
+	s.Equal(GlobalVariable, got)
+}
+
+func (s *VariableTestSuite) TestUTF16String() {
+	v := NewVariable("TestVar", testGuid, defaultAttrs, UTF16StringMarshaller, UTF16StringUnmarshaller)
+
+	s.Run("SetGet", func() {
+		var tests = []*testRow[string]{
+			{v, "Empty", "0000", "", assert.NoError},
+			{v, "Text", "6100e400" + "0000", "aä", assert.NoError},
+		}
+
+		runTests(s.T(), tests, func(t *testing.T, row *testRow[string], env *testEnv[string]) {
+			if !env.testSet(row) {
+				return
+			}
+			env.testGet(row)
+		})
+	})
+
+	s.Run("Get/Invalid", func() {
+		rows := []*testRow[string]{
+			{v, "Unterminated", "6100", "", wantedError(efireader.ErrMissingTerminator)},
+			{v, "Surrogate", "00de0000", "", wantedError(efireader.ErrInvalidSurrogate)},
+		}
+
+		runTests(s.T(), rows, func(t *testing.T, row *testRow[string], env *testEnv[string]) {
+			if !assert.NoError(t, env.setupVarFile(row)) {
+				return
+			}
+			env.testGet(row)
+		})
+	})
+}
+
+type codecData struct {
+	Version uint8
+	Name    string `efi:"utf16z"`
+	Data    []byte `efi:"rest"`
+}
+
+func (s *VariableTestSuite) TestCodec() {
+	v := NewVariable("TestVar", testGuid, defaultAttrs, CodecMarshaller[codecData], CodecUnmarshaller[codecData])
+
+	var tests = []*testRow[*codecData]{
+		{v, "One", "01" + "61000000" + "aabb", &codecData{1, "a", []byte{0xaa, 0xbb}}, assert.NoError},
+	}
+
+	runTests(s.T(), tests, func(t *testing.T, row *testRow[*codecData], env *testEnv[*codecData]) {
+		if !env.testSet(row) {
+			return
+		}
+		env.testGet(row)
+	})
+}
+
+func (s *VariableTestSuite) TestDelete() {
+	env := newTestEnv[uint16](s.T())
+
+	ok, err := BootNext.Exists(env.ctx)
+	s.Require().NoError(err)
+	s.False(ok)
+
+	got, err := BootNext.GetOrDefault(env.ctx, 0xffff)
+	s.Require().NoError(err)
+	s.Equal(uint16(0xffff), got)
+
+	err = BootNext.Delete(env.ctx)
+	s.ErrorIs(err, efivario.ErrNotFound)
+	s.ErrorContains(err, "efivars/delete(BootNext-"+GlobalVariable.String()+")")
+
+	s.Require().NoError(BootNext.Set(env.ctx, 2))
+
+	ok, err = BootNext.Exists(env.ctx)
+	s.Require().NoError(err)
+	s.True(ok)
+
+	got, err = BootNext.GetOrDefault(env.ctx, 0xffff)
+	s.Require().NoError(err)
+	s.Equal(uint16(2), got)
+
+	s.Require().NoError(BootNext.Delete(env.ctx))
+
+	ok, err = BootNext.Exists(env.ctx)
+	s.Require().NoError(err)
+	s.False(ok)
+}
+
+func (s *VariableTestSuite) TestUnsupported() {
+	env := newTestEnv[uint16](s.T())
+	v := NewVariable[uint16]("TestVar", testGuid, defaultAttrs, nil, nil)
+
+	_, _, err := v.Get(env.ctx)
+	s.ErrorIs(err, ErrUnsupported)
+	s.ErrorContains(err, "efivars/get(TestVar-"+testGuid.String()+")")
+	s.ErrorIs(v.Set(env.ctx, 1), ErrUnsupported)
+
+	_, err = v.GetOrDefault(env.ctx, 1)
+	s.ErrorIs(err, ErrUnsupported)
+}
+
 func TestVariablesTestSuite(t *testing.T) {
 	suite.Run(t, &VariableTestSuite{})
 }
@@ -0,0 +1,137 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efiwriter
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+)
+
+var ErrLengthOverflow = errors.New("length exceeds field size")
+
+// Buffer is an in-memory io.Writer which allows back-patching fields
+// written earlier, like the length of data following a field.
+//
+//	var b efiwriter.Buffer
+//	length := b.ReserveUint16()
+//	start := b.Len()
+//	...
+//	if err := length.SetLength(start); err != nil {
+//		return err
+//	}
+type Buffer struct {
+	buf []byte
+}
+
+func (b *Buffer) Write(p []byte) (n int, err error) {
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// Len returns the number of bytes written to the buffer.
+func (b *Buffer) Len() int {
+	return len(b.buf)
+}
+
+// Bytes returns the contents of the buffer.  The slice is only valid
+// until the next write to the buffer.
+func (b *Buffer) Bytes() []byte {
+	return b.buf
+}
+
+// Reset empties the buffer, retaining its memory.
+func (b *Buffer) Reset() {
+	b.buf = b.buf[:0]
+}
+
+// WriteTo writes the contents of the buffer to w.
+func (b *Buffer) WriteTo(w io.Writer) (n int64, err error) {
+	m, err := w.Write(b.buf)
+	return int64(m), err
+}
+
+// ReserveUint16 writes a zero 16-bit field to the buffer, which can
+// be set later through the returned Uint16Field.
+func (b *Buffer) ReserveUint16() Uint16Field {
+	f := Uint16Field{b: b, off: len(b.buf)}
+	b.buf = append(b.buf, 0x00, 0x00)
+	return f
+}
+
+// Uint16Field is a little endian 16-bit field reserved in a Buffer.
+type Uint16Field struct {
+	b   *Buffer
+	off int
+}
+
+// Offset returns the offset of the field within the buffer.
+func (f Uint16Field) Offset() int {
+	return f.off
+}
+
+// Set sets the field to v.
+func (f Uint16Field) Set(v uint16) {
+	binary.LittleEndian.PutUint16(f.b.buf[f.off:], v)
+}
+
+// SetLength sets the field to the number of bytes written to the
+// buffer since the offset start.  ErrLengthOverflow is returned if
+// the length does not fit into the field.
+func (f Uint16Field) SetLength(start int) error {
+	n := f.b.Len() - start
+	if n > math.MaxUint16 {
+		return ErrLengthOverflow
+	}
+	f.Set(uint16(n))
+	return nil
+}
+
+// ReserveUint32 writes a zero 32-bit field to the buffer, which can
+// be set later through the returned Uint32Field.
+func (b *Buffer) ReserveUint32() Uint32Field {
+	f := Uint32Field{b: b, off: len(b.buf)}
+	b.buf = append(b.buf, 0x00, 0x00, 0x00, 0x00)
+	return f
+}
+
+// Uint32Field is a little endian 32-bit field reserved in a Buffer.
+type Uint32Field struct {
+	b   *Buffer
+	off int
+}
+
+// Offset returns the offset of the field within the buffer.
+func (f Uint32Field) Offset() int {
+	return f.off
+}
+
+// Set sets the field to v.
+func (f Uint32Field) Set(v uint32) {
+	binary.LittleEndian.PutUint32(f.b.buf[f.off:], v)
+}
+
+// SetLength sets the field to the number of bytes written to the
+// buffer since the offset start.  ErrLengthOverflow is returned if
+// the length does not fit into the field.
+func (f Uint32Field) SetLength(start int) error {
+	n := uint64(f.b.Len() - start)
+	if n > math.MaxUint32 {
+		return ErrLengthOverflow
+	}
+	f.Set(uint32(n))
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efiwriter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestBuffer_ReserveUint16(t *testing.T) {
+	var b Buffer
+	b.Write([]byte{0xaa})
+	length := b.ReserveUint16()
+	start := b.Len()
+	b.Write([]byte{1, 2, 3})
+
+	if err := length.SetLength(start); err != nil {
+		t.Fatalf("SetLength() error = %v", err)
+	}
+	if want := []byte{0xaa, 3, 0, 1, 2, 3}; !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("Bytes() = %v, want %v", b.Bytes(), want)
+	}
+	if length.Offset() != 1 {
+		t.Errorf("Offset() = %d, want 1", length.Offset())
+	}
+
+	b.Write(make([]byte, 0x10000))
+	if err := length.SetLength(start); !errors.Is(err, ErrLengthOverflow) {
+		t.Errorf("SetLength() error = %v, want %v", err, ErrLengthOverflow)
+	}
+
+	var out bytes.Buffer
+	b.Reset()
+	b.Write([]byte{4})
+	if n, err := b.WriteTo(&out); err != nil || n != 1 || !bytes.Equal(out.Bytes(), []byte{4}) {
+		t.Errorf("WriteTo() = %d, %v, wrote %v", n, err, out.Bytes())
+	}
+}
+
+func TestBuffer_ReserveUint32(t *testing.T) {
+	var b Buffer
+	length := b.ReserveUint32()
+	b.Write([]byte{1, 2})
+
+	if err := length.SetLength(length.Offset() + 4); err != nil {
+		t.Fatalf("SetLength() error = %v", err)
+	}
+	if want := []byte{2, 0, 0, 0, 1, 2}; !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("Bytes() = %v, want %v", b.Bytes(), want)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package efiwriter implements the counterpart of efireader for
+// encoding EFI structures to their binary representation.
+package efiwriter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+type FieldWriter struct {
+	writer io.Writer
+	offset *int64
+}
+
+func (w *FieldWriter) Write(p []byte) (n int, err error) {
+	n, err = w.writer.Write(p)
+	*w.offset += int64(n)
+	return
+}
+
+func (w *FieldWriter) Offset() int64 {
+	return *w.offset
+}
+
+func (w *FieldWriter) WriteFields(fields ...any) (err error) {
+	for i, d := range fields {
+		if err = w.writeField(d); err != nil {
+			err = fmt.Errorf("field #%d: %w", i, err)
+			return
+		}
+	}
+	return
+}
+
+// WriteGUID writes the GUID g in its binary representation.
+func (w *FieldWriter) WriteGUID(g efiguid.GUID) error {
+	_, err := w.Write(g[:])
+	return err
+}
+
+// WriteUTF16Z writes s as null character terminated UTF-16 string.
+func (w *FieldWriter) WriteUTF16Z(s string) error {
+	b, err := efireader.EncodeUTF16Z(s, efireader.UTF16)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// WriteASCIIZ writes s as null byte terminated ASCII string.
+func (w *FieldWriter) WriteASCIIZ(s string) error {
+	b, err := StringToASCIIZBytes(s)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// writeField writes a single field in little endian byte order.
+// Unsigned integers, byte slices and byte arrays, the most common
+// fields, are encoded without the allocations binary.Write needs for
+// them.  UTF16ZString and ASCIIZString fields are encoded as null
+// terminated strings.
+func (w *FieldWriter) writeField(d any) (err error) {
+	var buf [8]byte
+	switch v := d.(type) {
+	case uint8:
+		buf[0] = v
+		_, err = w.Write(buf[:1])
+	case uint16:
+		binary.LittleEndian.PutUint16(buf[:], v)
+		_, err = w.Write(buf[:2])
+	case uint32:
+		binary.LittleEndian.PutUint32(buf[:], v)
+		_, err = w.Write(buf[:4])
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], v)
+		_, err = w.Write(buf[:8])
+	case []byte:
+		_, err = w.Write(v)
+	case efiguid.GUID:
+		err = w.WriteGUID(v)
+	case UTF16ZString:
+		err = w.WriteUTF16Z(string(v))
+	case ASCIIZString:
+		err = w.WriteASCIIZ(string(v))
+	default:
+		// Byte arrays, like addresses, are written in place.
+		if rv := reflect.ValueOf(d); rv.Kind() == reflect.Array && rv.Type().Elem().Kind() == reflect.Uint8 {
+			if !rv.CanAddr() {
+				rv = reflect.New(rv.Type()).Elem()
+				rv.Set(reflect.ValueOf(d))
+			}
+			_, err = w.Write(rv.Slice(0, rv.Len()).Bytes())
+			return
+		}
+		err = binary.Write(w, binary.LittleEndian, d)
+	}
+	return
+}
+
+func NewFieldWriter(writer io.Writer, offset *int64) *FieldWriter {
+	if offset == nil {
+		offset = new(int64)
+	}
+	return &FieldWriter{writer: writer, offset: offset}
+}
+
+// WriteFields writes the given fields to w in little endian byte
+// order and returns the number of bytes written.
+func WriteFields(w io.Writer, fields ...any) (n int64, err error) {
+	err = NewFieldWriter(w, &n).WriteFields(fields...)
+	return
+}
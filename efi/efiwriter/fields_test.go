@@ -0,0 +1,107 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efiwriter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/0x5a17ed/uefi/efi/efiguid"
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+func TestWriteFields(t *testing.T) {
+	type pair struct{ A, B uint8 }
+
+	var (
+		u8   uint8
+		u16  uint16
+		u32  uint32
+		u64  uint64
+		arr  [3]byte
+		g    efiguid.GUID
+		st   pair
+		i16  int16
+		s    []byte
+		guid = efiguid.MustFromString("8be4df61-93ca-11d2-aa0d-00e098032b8c")
+	)
+
+	var buf bytes.Buffer
+	n, err := WriteFields(&buf, uint8(1), uint16(2), uint32(3), uint64(4), [3]byte{5, 6, 7}, guid, pair{8, 9}, int16(-2))
+	if err != nil {
+		t.Fatalf("WriteFields() error = %v", err)
+	}
+	if n != int64(buf.Len()) || n != 1+2+4+8+3+16+2+2 {
+		t.Errorf("WriteFields() n = %d, len = %d", n, buf.Len())
+	}
+
+	// Reading the fields back has to yield the values written.
+	if _, err := efireader.ReadFields(&buf, &u8, &u16, &u32, &u64, &arr, &g, &st, &i16); err != nil {
+		t.Fatalf("ReadFields() error = %v", err)
+	}
+	if u8 != 1 || u16 != 2 || u32 != 3 || u64 != 4 || arr != [3]byte{5, 6, 7} || g != guid || st != (pair{8, 9}) || i16 != -2 {
+		t.Errorf("ReadFields() = %v %v %v %v %v %v %v %v", u8, u16, u32, u64, arr, g, st, i16)
+	}
+
+	buf.Reset()
+	if _, err := WriteFields(&buf, UTF16ZString("a"), ASCIIZString("b"), []byte{0xff}); err != nil {
+		t.Fatalf("WriteFields() error = %v", err)
+	}
+	if s = buf.Bytes(); !bytes.Equal(s, []byte{'a', 0, 0, 0, 'b', 0, 0xff}) {
+		t.Errorf("WriteFields() = %v", s)
+	}
+
+	if _, err := WriteFields(&buf, uint8(0), ASCIIZString("ä")); !errors.Is(err, ErrNotASCII) {
+		t.Errorf("WriteFields() error = %v, want %v", err, ErrNotASCII)
+	} else if err.Error() != "field #1: "+ErrNotASCII.Error() {
+		t.Errorf("WriteFields() error = %q", err)
+	}
+}
+
+func TestFieldWriter_Offset(t *testing.T) {
+	var (
+		buf bytes.Buffer
+		n   int64 = 2
+	)
+	w := NewFieldWriter(&buf, &n)
+	if err := w.WriteGUID(efiguid.GUID{}); err != nil {
+		t.Fatalf("WriteGUID() error = %v", err)
+	}
+	if err := w.WriteUTF16Z("ab"); err != nil {
+		t.Fatalf("WriteUTF16Z() error = %v", err)
+	}
+	if err := w.WriteASCIIZ("ab"); err != nil {
+		t.Fatalf("WriteASCIIZ() error = %v", err)
+	}
+	if got, want := w.Offset(), int64(2+16+6+3); got != want {
+		t.Errorf("Offset() = %d, want %d", got, want)
+	}
+}
+
+func TestFieldWriter_WriteUTF16Z(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFieldWriter(&buf, nil)
+	if err := w.WriteUTF16Z("a😀"); err != nil {
+		t.Fatalf("WriteUTF16Z() error = %v", err)
+	}
+	if want := []byte{'a', 0, 0x3d, 0xd8, 0x00, 0xde, 0, 0}; !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("WriteUTF16Z() = %v, want %v", buf.Bytes(), want)
+	}
+
+	if err := w.WriteUTF16Z("a\x00b"); !errors.Is(err, ErrNullCharacter) {
+		t.Errorf("WriteUTF16Z() error = %v, want %v", err, ErrNullCharacter)
+	}
+}
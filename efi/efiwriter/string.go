@@ -0,0 +1,72 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efiwriter
+
+import (
+	"bytes"
+
+	"github.com/0x5a17ed/uefi/efi/efireader"
+)
+
+var (
+	ErrNullCharacter = efireader.ErrNullCharacter
+	ErrNotASCII      = efireader.ErrNotASCII
+)
+
+// UTF16ZString is a field written by WriteFields as null character
+// terminated UTF-16 string.
+type UTF16ZString string
+
+// ASCIIZString is a field written by WriteFields as null byte
+// terminated ASCII string.
+type ASCIIZString string
+
+// StringToASCIIZBytes encodes s to a null byte terminated ASCII byte
+// sequence.
+func StringToASCIIZBytes(s string) ([]byte, error) {
+	out := make([]byte, 0, len(s)+1)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == 0x00:
+			return nil, ErrNullCharacter
+		case c >= 0x80:
+			return nil, ErrNotASCII
+		}
+	}
+	return append(append(out, s...), 0x00), nil
+}
+
+// TerminateASCII returns the ASCII byte sequence b with a trailing
+// null byte appended if it is not terminated already.
+func TerminateASCII(b []byte) []byte {
+	if len(b) > 0 && b[len(b)-1] == 0x00 {
+		return b
+	}
+	return append(b[:len(b):len(b)], 0x00)
+}
+
+// TerminateUTF16 returns the UTF-16 byte sequence b with a trailing
+// null character appended if it is not terminated already.  A byte
+// sequence of odd length is padded with a null byte to complete its
+// last character first.
+func TerminateUTF16(b []byte) []byte {
+	if len(b)%2 != 0 {
+		b = append(b[:len(b):len(b)], 0x00)
+	}
+	if len(b) >= 2 && bytes.HasSuffix(b, []byte{0x00, 0x00}) {
+		return b
+	}
+	return append(b[:len(b):len(b)], 0x00, 0x00)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2022 Arthur Skowronek <0x5a17ed@tuta.io> and contributors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// <https://www.apache.org/licenses/LICENSE-2.0>
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package efiwriter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTerminateASCII(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  []byte
+		want []byte
+	}{
+		{"empty", nil, []byte{0}},
+		{"terminated", []byte{'a', 0}, []byte{'a', 0}},
+		{"unterminated", []byte{'a'}, []byte{'a', 0}},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TerminateASCII(tc.inp); !bytes.Equal(got, tc.want) {
+				t.Errorf("TerminateASCII() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTerminateUTF16(t *testing.T) {
+	tt := []struct {
+		name string
+		inp  []byte
+		want []byte
+	}{
+		{"empty", nil, []byte{0, 0}},
+		{"terminated", []byte{'a', 0, 0, 0}, []byte{'a', 0, 0, 0}},
+		{"unterminated", []byte{'a', 0}, []byte{'a', 0, 0, 0}},
+		{"odd length", []byte{'a'}, []byte{'a', 0, 0, 0}},
+		{"odd length terminated", []byte{'a', 0, 0}, []byte{'a', 0, 0, 0}},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := TerminateUTF16(tc.inp); !bytes.Equal(got, tc.want) {
+				t.Errorf("TerminateUTF16() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStringToASCIIZBytes(t *testing.T) {
+	tt := []struct {
+		name    string
+		inp     string
+		want    []byte
+		wantErr error
+	}{
+		{"empty", "", []byte{0}, nil},
+		{"golden path", "PNP0A03", []byte("PNP0A03\x00"), nil},
+		{"null character", "a\x00", nil, ErrNullCharacter},
+		{"non-ascii", "ä", nil, ErrNotASCII},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := StringToASCIIZBytes(tc.inp)
+			if err != tc.wantErr || !bytes.Equal(got, tc.want) {
+				t.Errorf("StringToASCIIZBytes() = %v, %v, want %v, %v", got, err, tc.want, tc.wantErr)
+			}
+		})
+	}
+}